@@ -9,8 +9,11 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
+	// the runtime image has no zoneinfo; user time zones are resolved from the embedded database
+	_ "time/tzdata"
 
 	_ "github.com/lib/pq"
 	"github.com/neizhmak/avito-review-service/internal/service"
@@ -29,6 +32,42 @@ func main() {
 		port = "8080"
 	}
 
+	pendingInterval := 30 * time.Second
+	if v := os.Getenv("PENDING_REVIEWERS_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			log.Fatalf("invalid PENDING_REVIEWERS_INTERVAL %q", v)
+		}
+		pendingInterval = d
+	}
+
+	escalationInterval := 5 * time.Minute
+	if v := os.Getenv("SLA_ESCALATION_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			log.Fatalf("invalid SLA_ESCALATION_INTERVAL %q", v)
+		}
+		escalationInterval = d
+	}
+
+	webhookInterval := 10 * time.Second
+	if v := os.Getenv("WEBHOOK_DELIVERY_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			log.Fatalf("invalid WEBHOOK_DELIVERY_INTERVAL %q", v)
+		}
+		webhookInterval = d
+	}
+
+	allowPrivateWebhooks := false
+	if v := os.Getenv("WEBHOOK_ALLOW_PRIVATE_TARGETS"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			log.Fatalf("invalid WEBHOOK_ALLOW_PRIVATE_TARGETS %q", v)
+		}
+		allowPrivateWebhooks = b
+	}
+
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
 	slog.SetDefault(logger)
 
@@ -52,9 +91,21 @@ func main() {
 	teamStorage := postgres.NewTeamStorage(db)
 	userStorage := postgres.NewUserStorage(db)
 	prStorage := postgres.NewPullRequestStorage(db)
+	webhookStorage := postgres.NewWebhookStorage(db)
 
 	// initialize service
 	prService := service.NewPRService(prStorage, userStorage, teamStorage, db)
+	prService.SetWebhookStorage(webhookStorage)
+	if allowPrivateWebhooks {
+		prService.AllowPrivateWebhooks()
+	}
+
+	// background workers filling PRs that are waiting for reviewers, escalating overdue reviews and delivering webhooks
+	workerCtx, stopWorker := context.WithCancel(context.Background())
+	defer stopWorker()
+	go prService.RunPendingReviewerWorker(workerCtx, pendingInterval)
+	go prService.RunEscalationWorker(workerCtx, escalationInterval)
+	go prService.RunWebhookWorker(workerCtx, webhookInterval)
 
 	// initialize handler (HTTP)
 	handler := rest.NewHandler(prService)
@@ -80,6 +131,7 @@ func main() {
 	<-stop
 
 	logger.Info("shutting down server")
+	stopWorker()
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	if err := server.Shutdown(ctx); err != nil {
@@ -1,37 +1,542 @@
 package domain
 
-import "time"
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
 
 type PRStatus string
 
 const (
+	PRStatusDraft  PRStatus = "DRAFT"
 	PRStatusOpen   PRStatus = "OPEN"
 	PRStatusMerged PRStatus = "MERGED"
+	PRStatusClosed PRStatus = "CLOSED"
+)
+
+// ReviewState is the outcome of a single reviewer's review.
+type ReviewState string
+
+const (
+	ReviewStatePending          ReviewState = "PENDING"
+	ReviewStateApproved         ReviewState = "APPROVED"
+	ReviewStateChangesRequested ReviewState = "CHANGES_REQUESTED"
+	ReviewStateDismissed        ReviewState = "DISMISSED"
 )
 
+// IsValid reports whether the state is one of the known review states.
+func (s ReviewState) IsValid() bool {
+	switch s {
+	case ReviewStatePending, ReviewStateApproved, ReviewStateChangesRequested, ReviewStateDismissed:
+		return true
+	}
+	return false
+}
+
+// DeclineReason explains why a reviewer declined an assignment.
+type DeclineReason string
+
+const (
+	DeclineReasonNoContext          DeclineReason = "NO_CONTEXT"
+	DeclineReasonOverloaded         DeclineReason = "OVERLOADED"
+	DeclineReasonConflictOfInterest DeclineReason = "CONFLICT_OF_INTEREST"
+)
+
+// IsValid reports whether the reason is one of the known decline reasons.
+func (r DeclineReason) IsValid() bool {
+	switch r {
+	case DeclineReasonNoContext, DeclineReasonOverloaded, DeclineReasonConflictOfInterest:
+		return true
+	}
+	return false
+}
+
+// ReviewerStrategy names the algorithm a team uses to pick reviewers.
+type ReviewerStrategy string
+
+const (
+	StrategyRandom      ReviewerStrategy = "random"
+	StrategyRoundRobin  ReviewerStrategy = "round_robin"
+	StrategyLeastLoaded ReviewerStrategy = "least_loaded"
+
+	DefaultReviewerStrategy = StrategyLeastLoaded
+)
+
+// UserRole is the seniority of a user.
+type UserRole string
+
+const (
+	RoleJunior UserRole = "junior"
+	RoleMiddle UserRole = "middle"
+	RoleSenior UserRole = "senior"
+	RoleLead   UserRole = "lead"
+
+	DefaultUserRole = RoleMiddle
+)
+
+// IsValid reports whether the role is one of the known roles.
+func (r UserRole) IsValid() bool {
+	switch r {
+	case RoleJunior, RoleMiddle, RoleSenior, RoleLead:
+		return true
+	}
+	return false
+}
+
+// IsSenior reports whether the role counts as a senior reviewer.
+func (r UserRole) IsSenior() bool {
+	return r == RoleSenior || r == RoleLead
+}
+
 // Team represents a group of users working together.
 type Team struct {
-	Name    string `json:"team_name"`
-	Members []User `json:"members,omitempty"`
+	Name     string        `json:"team_name"`
+	Settings *TeamSettings `json:"settings,omitempty"`
+	Members  []User        `json:"members,omitempty"`
+}
+
+// TeamSettings holds the reviewer assignment policy of a team.
+type TeamSettings struct {
+	TeamName               string           `json:"team_name"`
+	ReviewerCount          int              `json:"reviewer_count"`
+	MinReviewers           int              `json:"min_reviewers"`
+	ReviewerStrategy       ReviewerStrategy `json:"reviewer_strategy"`
+	AllowCrossTeamFallback bool             `json:"allow_cross_team_fallback"`
+	// FallbackTeams lists, in order of preference, the teams missing reviewers are taken from.
+	FallbackTeams []string `json:"fallback_teams"`
+	// RequiredApprovals and BlockOnChangesRequested form the merge policy of the team's pull requests.
+	RequiredApprovals       int  `json:"required_approvals"`
+	BlockOnChangesRequested bool `json:"block_on_changes_requested"`
+	// MaxOpenReviews is the default number of OPEN pull requests a member may review at once; 0 means unlimited.
+	MaxOpenReviews int `json:"max_open_reviews"`
+	// QueueWhenFull lets pull requests be created with missing reviewers when every candidate is at capacity.
+	QueueWhenFull bool `json:"queue_when_full"`
+	// PairingWindowDays is how far back reviews of the same author are counted to make picking that reviewer
+	// less likely; 0 disables the weighting.
+	PairingWindowDays int `json:"pairing_window_days"`
+	// RequireSenior makes at least one of the required reviewers a senior or lead.
+	RequireSenior bool `json:"require_senior"`
+	// ShadowReviewer adds a junior from the team as a non-blocking shadow reviewer to new pull requests.
+	ShadowReviewer bool `json:"shadow_reviewer"`
+	// ReviewerCountBySize overrides ReviewerCount for pull requests of the given sizes.
+	ReviewerCountBySize map[PRSize]int `json:"reviewer_count_by_size,omitempty"`
+	// ReviewSLAHours is how many working hours a reviewer has to review after being assigned; 0 disables the SLA.
+	ReviewSLAHours int `json:"review_sla_hours"`
+	// SLAEscalation is what happens to an overdue review; empty means EscalationNotify.
+	SLAEscalation SLAEscalation `json:"sla_escalation,omitempty"`
+}
+
+// SLAEscalation is the action taken when a review misses its SLA.
+type SLAEscalation string
+
+const (
+	// EscalationNotify only reports the overdue review.
+	EscalationNotify SLAEscalation = "NOTIFY"
+	// EscalationReassign hands the review over to another reviewer, as Reassign does.
+	EscalationReassign SLAEscalation = "REASSIGN"
+	// EscalationAddLead adds a lead of the author team as an extra reviewer.
+	EscalationAddLead SLAEscalation = "ADD_LEAD"
+)
+
+// IsValid reports whether the escalation is one of the known actions.
+func (e SLAEscalation) IsValid() bool {
+	switch e {
+	case EscalationNotify, EscalationReassign, EscalationAddLead:
+		return true
+	}
+	return false
+}
+
+// ReviewerCountFor returns how many reviewers a pull request of the given size gets. Pull requests of unknown size
+// and sizes without an override get ReviewerCount.
+func (s TeamSettings) ReviewerCountFor(size PRSize) int {
+	if count, ok := s.ReviewerCountBySize[size]; ok && size != "" {
+		return count
+	}
+	return s.ReviewerCount
+}
+
+// PRSize is the size bucket of a pull request, derived from its diff stats.
+type PRSize string
+
+const (
+	PRSizeXS PRSize = "XS"
+	PRSizeS  PRSize = "S"
+	PRSizeM  PRSize = "M"
+	PRSizeL  PRSize = "L"
+	PRSizeXL PRSize = "XL"
+)
+
+// IsValid reports whether the size is one of the known buckets.
+func (s PRSize) IsValid() bool {
+	switch s {
+	case PRSizeXS, PRSizeS, PRSizeM, PRSizeL, PRSizeXL:
+		return true
+	}
+	return false
+}
+
+// Weight is how much a review of a pull request of this size counts towards reviewer load.
+// Pull requests of unknown size weigh as much as medium ones.
+func (s PRSize) Weight() int {
+	switch s {
+	case PRSizeXS:
+		return 1
+	case PRSizeS:
+		return 2
+	case PRSizeL:
+		return 5
+	case PRSizeXL:
+		return 8
+	}
+	return 3
+}
+
+// DiffStats describes the changes of a pull request.
+type DiffStats struct {
+	LinesAdded   int `json:"lines_added"`
+	LinesRemoved int `json:"lines_removed"`
+	FilesChanged int `json:"files_changed"`
+}
+
+// Size returns the size bucket for the number of changed lines: up to 10 is XS, up to 50 is S, up to 250 is M,
+// up to 1000 is L and anything bigger is XL.
+func (d DiffStats) Size() PRSize {
+	switch lines := d.LinesAdded + d.LinesRemoved; {
+	case lines <= 10:
+		return PRSizeXS
+	case lines <= 50:
+		return PRSizeS
+	case lines <= 250:
+		return PRSizeM
+	case lines <= 1000:
+		return PRSizeL
+	}
+	return PRSizeXL
+}
+
+// DefaultTeamSettings returns the policy applied to teams that have not configured their own.
+func DefaultTeamSettings(teamName string) TeamSettings {
+	return TeamSettings{
+		TeamName:         teamName,
+		ReviewerCount:    2,
+		MinReviewers:     0,
+		ReviewerStrategy: DefaultReviewerStrategy,
+		FallbackTeams:    []string{},
+	}
 }
 
 // User represents an individual user in the system.
 type User struct {
-	ID       string `json:"user_id"`
-	Username string `json:"username"`
-	IsActive bool   `json:"is_active"`
+	ID       string   `json:"user_id"`
+	Username string   `json:"username"`
+	IsActive bool     `json:"is_active"`
+	TeamName string   `json:"team_name"`
+	Role     UserRole `json:"role"`
+	// MaxOpenReviews overrides the team's review capacity for this user when set.
+	MaxOpenReviews *int `json:"max_open_reviews,omitempty"`
+	// Skills are tags matched against pull request labels when reviewers are picked.
+	Skills []string `json:"skills,omitempty"`
+	// TimeZone, WorkStartHour, WorkEndHour and WorkDays form the working schedule of the user in their local time.
+	// Reviewers inside working hours are preferred and review SLAs only count working time.
+	TimeZone      string `json:"time_zone"`
+	WorkStartHour int    `json:"work_start_hour"`
+	WorkEndHour   int    `json:"work_end_hour"`
+	// WorkDays are ISO weekday numbers: 1 is Monday, 7 is Sunday.
+	WorkDays []int `json:"work_days"`
+}
+
+// Default working schedule of users that have not configured their own.
+const (
+	DefaultTimeZone      = "UTC"
+	DefaultWorkStartHour = 9
+	DefaultWorkEndHour   = 18
+)
+
+// DefaultWorkDays returns Monday to Friday.
+func DefaultWorkDays() []int {
+	return []int{1, 2, 3, 4, 5}
+}
+
+// ApplyScheduleDefaults fills the unset parts of the user's working schedule with the defaults.
+func (u *User) ApplyScheduleDefaults() {
+	if u.TimeZone == "" {
+		u.TimeZone = DefaultTimeZone
+	}
+	if u.WorkStartHour == 0 && u.WorkEndHour == 0 {
+		u.WorkStartHour, u.WorkEndHour = DefaultWorkStartHour, DefaultWorkEndHour
+	}
+	if len(u.WorkDays) == 0 {
+		u.WorkDays = DefaultWorkDays()
+	}
+}
+
+// UserUpdate lists the profile fields of a user to change; nil fields are left as they are.
+type UserUpdate struct {
+	Username      *string
+	Skills        *[]string
+	TimeZone      *string
+	WorkStartHour *int
+	WorkEndHour   *int
+	WorkDays      *[]int
+}
+
+// Holiday is a day off for the whole team; its members are not working on that date in their own time zone.
+type Holiday struct {
 	TeamName string `json:"team_name"`
+	// Date is formatted as YYYY-MM-DD.
+	Date string `json:"date"`
+	Name string `json:"name,omitempty"`
+}
+
+// HasSkill reports whether the user has any of the given tags.
+func (u User) HasSkill(tags []string) bool {
+	for _, skill := range u.Skills {
+		for _, tag := range tags {
+			if skill == tag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NormalizeTags lowercases and trims skill tags and labels, dropping empty and repeated ones.
+func NormalizeTags(tags []string) []string {
+	result := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	return result
+}
+
+// Unavailability is a period during which a user must not be picked as a reviewer.
+type Unavailability struct {
+	ID       int64     `json:"id"`
+	UserID   string    `json:"user_id"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Reason   string    `json:"reason,omitempty"`
+}
+
+// ExclusionRule forbids a user from reviewing another user's pull requests, until a given time or for good.
+type ExclusionRule struct {
+	ID         int64  `json:"id"`
+	ReviewerID string `json:"reviewer_id"`
+	AuthorID   string `json:"author_id"`
+	// Mutual applies the rule in both directions, keeping the pair apart.
+	Mutual bool       `json:"mutual"`
+	Until  *time.Time `json:"until,omitempty"`
+	Reason string     `json:"reason,omitempty"`
 }
 
 // PullRequest represents a pull request in the system.
 type PullRequest struct {
-	ID        string     `json:"pull_request_id"`
-	Title     string     `json:"pull_request_name"`
-	AuthorID  string     `json:"author_id"`
-	Status    PRStatus   `json:"status"`
-	Reviewers []string   `json:"assigned_reviewers"`
-	CreatedAt *time.Time `json:"createdAt,omitempty"`
-	MergedAt  *time.Time `json:"mergedAt,omitempty"`
+	ID       string   `json:"pull_request_id"`
+	Title    string   `json:"pull_request_name"`
+	AuthorID string   `json:"author_id"`
+	Status   PRStatus `json:"status"`
+	// Reviewers are encoded as reviews; assigned_reviewers keeps listing their ids, see MarshalJSON.
+	Reviewers    []AssignedReviewer `json:"reviews"`
+	ChangedFiles []string           `json:"changed_files,omitempty"`
+	CreatedAt    *time.Time         `json:"createdAt,omitempty"`
+	MergedAt     *time.Time         `json:"mergedAt,omitempty"`
+	ClosedAt     *time.Time         `json:"closedAt,omitempty"`
+	// ForceMerged is set when the pull request was merged bypassing the merge policy.
+	ForceMerged bool `json:"force_merged,omitempty"`
+	// NeedsReviewers is set while the pull request has fewer reviewers than its team's reviewer_count.
+	NeedsReviewers bool `json:"needs_reviewers,omitempty"`
+	// RequestedReviewers are assigned first when reviewers are picked, if they are eligible.
+	RequestedReviewers []string `json:"requested_reviewers,omitempty"`
+	// ExcludedReviewers are never picked automatically for the pull request.
+	ExcludedReviewers []string `json:"excluded_reviewers,omitempty"`
+	// Labels are matched against reviewer skills; reviewers with a matching skill are preferred.
+	Labels []string `json:"labels,omitempty"`
+	// DiffStats are optional; when given, Size is derived from them and picks the reviewer count.
+	DiffStats *DiffStats `json:"diff_stats,omitempty"`
+	Size      PRSize     `json:"size,omitempty"`
+	// RejectedReviewers explains which requested reviewers were not assigned. It is only filled by the call
+	// that assigns the initial reviewers and is not stored.
+	RejectedReviewers []RejectedReviewer `json:"rejected_reviewers,omitempty"`
+}
+
+// MarshalJSON encodes the pull request with assigned_reviewers, the ids of its reviewers, next to their reviews.
+func (pr PullRequest) MarshalJSON() ([]byte, error) {
+	type plain PullRequest
+	if pr.Reviewers == nil {
+		pr.Reviewers = []AssignedReviewer{}
+	}
+	return json.Marshal(struct {
+		plain
+		AssignedReviewers []string `json:"assigned_reviewers"`
+	}{plain: plain(pr), AssignedReviewers: pr.ReviewerIDs()})
+}
+
+// RejectReason explains why a requested reviewer was not assigned.
+type RejectReason string
+
+const (
+	RejectReasonNotFound    RejectReason = "NOT_FOUND"
+	RejectReasonInactive    RejectReason = "INACTIVE"
+	RejectReasonAuthor      RejectReason = "AUTHOR"
+	RejectReasonExcluded    RejectReason = "EXCLUDED"
+	RejectReasonDuplicate   RejectReason = "DUPLICATE"
+	RejectReasonUnavailable RejectReason = "UNAVAILABLE"
+	RejectReasonAtCapacity  RejectReason = "AT_CAPACITY"
+	RejectReasonTooMany     RejectReason = "TOO_MANY"
+	RejectReasonConflict    RejectReason = "CONFLICT"
+	// RejectReasonSeniorRequired is given when accepting a non-senior would leave no slot for the required senior.
+	RejectReasonSeniorRequired RejectReason = "SENIOR_REQUIRED"
+)
+
+// RejectedReviewer is a requested reviewer that could not be assigned.
+type RejectedReviewer struct {
+	UserID string       `json:"user_id"`
+	Reason RejectReason `json:"reason"`
+}
+
+// AssignedReviewer is a reviewer assigned to a pull request together with the state of their review.
+type AssignedReviewer struct {
+	UserID         string      `json:"user_id"`
+	State          ReviewState `json:"state"`
+	AssignedAt     *time.Time  `json:"assigned_at,omitempty"`
+	StateUpdatedAt *time.Time  `json:"state_updated_at,omitempty"`
+	// FallbackTeam is set when the reviewer was borrowed from one of the author team's fallback teams.
+	FallbackTeam string `json:"fallback_team,omitempty"`
+	// Shadow reviewers take part for mentoring only: they do not count towards reviewer_count or the merge policy.
+	Shadow bool `json:"shadow"`
+	// DueAt is the review deadline following the author team's SLA; it is not set without an SLA.
+	DueAt *time.Time `json:"due_at,omitempty"`
+	// EscalatedAt is set once the overdue review has been escalated.
+	EscalatedAt *time.Time `json:"escalated_at,omitempty"`
+}
+
+// OverdueReview is a pending review of an OPEN pull request that is past its deadline.
+type OverdueReview struct {
+	PRID        string     `json:"pull_request_id"`
+	Title       string     `json:"pull_request_name"`
+	AuthorID    string     `json:"author_id"`
+	TeamName    string     `json:"team_name"`
+	ReviewerID  string     `json:"reviewer_id"`
+	AssignedAt  time.Time  `json:"assigned_at"`
+	DueAt       time.Time  `json:"due_at"`
+	EscalatedAt *time.Time `json:"escalated_at,omitempty"`
+}
+
+// ReviewEscalation records the action taken on an overdue review.
+type ReviewEscalation struct {
+	PRID       string        `json:"pull_request_id"`
+	ReviewerID string        `json:"reviewer_id"`
+	Action     SLAEscalation `json:"action"`
+	// NewReviewerID is the reviewer who took over or joined the review, if any.
+	NewReviewerID string `json:"new_reviewer_id,omitempty"`
+}
+
+// ReviewDecline records that a reviewer declined their assignment on a pull request.
+type ReviewDecline struct {
+	PRID       string        `json:"pull_request_id"`
+	UserID     string        `json:"user_id"`
+	Reason     DeclineReason `json:"reason"`
+	DeclinedAt time.Time     `json:"declined_at"`
+}
+
+// PREventType is the kind of change recorded in the history of a pull request.
+type PREventType string
+
+const (
+	PREventAssigned   PREventType = "ASSIGNED"
+	PREventReassigned PREventType = "REASSIGNED"
+	PREventRemoved    PREventType = "REMOVED"
+	PREventDeclined   PREventType = "DECLINED"
+	PREventMerged     PREventType = "MERGED"
+	// PREventEscalated records an overdue review escalation; its reason is the escalation action taken.
+	PREventEscalated PREventType = "ESCALATED"
+)
+
+// SystemActor is recorded as the actor of changes made by background workers and of requests that do not name
+// an actor.
+const SystemActor = "system"
+
+// PREvent is an entry of the append-only history of a pull request.
+type PREvent struct {
+	ID         int64       `json:"id"`
+	PRID       string      `json:"pull_request_id"`
+	Type       PREventType `json:"event_type"`
+	ReviewerID string      `json:"reviewer_id,omitempty"`
+	// PreviousReviewerID is the reviewer replaced by ReviewerID in a REASSIGNED event.
+	PreviousReviewerID string `json:"previous_reviewer_id,omitempty"`
+	Actor              string `json:"actor"`
+	Reason             string `json:"reason,omitempty"`
+	// Strategy tells how the reviewer was chosen: the reviewer strategy of the team, "requested" or "manual".
+	Strategy string `json:"strategy,omitempty"`
+	// Shadow marks events about a shadow reviewer.
+	Shadow    bool      `json:"shadow,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RequiredReviewerCount returns how many assigned reviewers are not shadows.
+func (pr PullRequest) RequiredReviewerCount() int {
+	n := 0
+	for _, r := range pr.Reviewers {
+		if !r.Shadow {
+			n++
+		}
+	}
+	return n
+}
+
+// IsShadowReviewer reports whether the user is assigned to the pull request as a shadow reviewer.
+func (pr PullRequest) IsShadowReviewer(userID string) bool {
+	for _, r := range pr.Reviewers {
+		if r.UserID == userID {
+			return r.Shadow
+		}
+	}
+	return false
+}
+
+// ReviewerIDs returns the user ids of the assigned reviewers.
+func (pr PullRequest) ReviewerIDs() []string {
+	ids := make([]string, 0, len(pr.Reviewers))
+	for _, r := range pr.Reviewers {
+		ids = append(ids, r.UserID)
+	}
+	return ids
+}
+
+// ReviewerReplacement describes what happened to one review of a reviewer removed from a pull request.
+type ReviewerReplacement struct {
+	PRID          string `json:"pull_request_id"`
+	OldReviewerID string `json:"old_reviewer_id"`
+	// NewReviewerID is empty when no replacement could be found.
+	NewReviewerID string `json:"new_reviewer_id,omitempty"`
+}
+
+// ReassignmentReport lists the reviews that were moved to another reviewer and those left unfilled.
+type ReassignmentReport struct {
+	Reassigned []ReviewerReplacement `json:"reassigned"`
+	Unfilled   []ReviewerReplacement `json:"unfilled"`
+}
+
+// PRReassignment is the outcome of replacing removed reviewers on a single pull request.
+type PRReassignment struct {
+	PRID       string                `json:"pull_request_id"`
+	Reassigned []ReviewerReplacement `json:"reassigned"`
+	Unfilled   []ReviewerReplacement `json:"unfilled"`
+}
+
+// CodeOwnerRule maps a CODEOWNERS-style path pattern to the users and teams owning matching files.
+type CodeOwnerRule struct {
+	Pattern string   `json:"pattern"`
+	Users   []string `json:"user_ids,omitempty"`
+	Teams   []string `json:"team_names,omitempty"`
 }
 
 // ReviewerStats represents statistics for a reviewer.
@@ -42,8 +547,16 @@ type ReviewerStats struct {
 
 // SystemStats represents overall system statistics.
 type SystemStats struct {
-	TotalPRs     int             `json:"total_prs"`
-	TopReviewers []ReviewerStats `json:"top_reviewers"`
+	TotalPRs         int                   `json:"total_prs"`
+	TopReviewers     []ReviewerStats       `json:"top_reviewers"`
+	DeclinesByReason map[DeclineReason]int `json:"declines_by_reason"`
+}
+
+// PairingMatrix counts, per author, how many of their pull requests each reviewer was assigned to.
+type PairingMatrix struct {
+	TeamName string                    `json:"team_name"`
+	Since    time.Time                 `json:"since"`
+	Pairs    map[string]map[string]int `json:"pairs"`
 }
 
 // PullRequestShort represents a summarized view of a pull request.
@@ -53,3 +566,76 @@ type PullRequestShort struct {
 	AuthorID string   `json:"author_id"`
 	Status   PRStatus `json:"status"`
 }
+
+// WebhookEventType names an event that webhook subscribers can receive.
+type WebhookEventType string
+
+const (
+	WebhookPRCreated          WebhookEventType = "pr.created"
+	WebhookReviewerAssigned   WebhookEventType = "reviewer.assigned"
+	WebhookReviewerReassigned WebhookEventType = "reviewer.reassigned"
+	WebhookPRMerged           WebhookEventType = "pr.merged"
+	WebhookTeamDeactivated    WebhookEventType = "team.deactivated"
+	WebhookReviewOverdue      WebhookEventType = "review.overdue"
+)
+
+// IsValid reports whether the event type is known.
+func (t WebhookEventType) IsValid() bool {
+	switch t {
+	case WebhookPRCreated, WebhookReviewerAssigned, WebhookReviewerReassigned, WebhookPRMerged, WebhookTeamDeactivated,
+		WebhookReviewOverdue:
+		return true
+	}
+	return false
+}
+
+// Webhook is a subscription of a URL to a set of event types.
+type Webhook struct {
+	ID         int64              `json:"id"`
+	URL        string             `json:"url"`
+	EventTypes []WebhookEventType `json:"event_types"`
+	// Secret signs the payloads; it is only returned when the webhook is created.
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Subscribes reports whether the webhook receives events of the given type.
+func (w Webhook) Subscribes(eventType WebhookEventType) bool {
+	for _, t := range w.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// DeliveryStatus is the state of a webhook delivery: PENDING until it succeeds or runs out of attempts.
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "PENDING"
+	DeliveryDelivered DeliveryStatus = "DELIVERED"
+	DeliveryFailed    DeliveryStatus = "FAILED"
+)
+
+// WebhookPayload is the JSON body posted to webhook subscribers.
+type WebhookPayload struct {
+	Event     WebhookEventType `json:"event"`
+	CreatedAt time.Time        `json:"created_at"`
+	Data      interface{}      `json:"data"`
+}
+
+// WebhookDelivery is one payload queued for one webhook, with the outcome of its latest attempt.
+type WebhookDelivery struct {
+	ID             int64            `json:"id"`
+	WebhookID      int64            `json:"webhook_id"`
+	EventType      WebhookEventType `json:"event_type"`
+	Payload        json.RawMessage  `json:"payload"`
+	Status         DeliveryStatus   `json:"status"`
+	Attempts       int              `json:"attempts"`
+	NextAttemptAt  time.Time        `json:"next_attempt_at"`
+	LastStatusCode int              `json:"last_status_code,omitempty"`
+	LastError      string           `json:"last_error,omitempty"`
+	CreatedAt      time.Time        `json:"created_at"`
+	DeliveredAt    *time.Time       `json:"delivered_at,omitempty"`
+}
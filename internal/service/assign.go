@@ -0,0 +1,818 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/neizhmak/avito-review-service/internal/domain"
+	"github.com/neizhmak/avito-review-service/internal/storage"
+)
+
+// assignment is a reviewer picked for a pull request.
+type assignment struct {
+	User domain.User
+	// FallbackTeam is set when the reviewer does not belong to the author's team.
+	FallbackTeam string
+	// Shadow marks a non-blocking reviewer added for mentoring.
+	Shadow bool
+	// Strategy and Reason tell how and why the reviewer was chosen; they are recorded in the pull request history.
+	Strategy string
+	Reason   string
+	// Replaces is the reviewer whose review this assignment takes over, if any.
+	Replaces string
+	// SeniorPending is set on reviewers picked while the senior required by the author team was not available.
+	SeniorPending bool
+}
+
+type pendingReviewsKey struct{}
+
+// pendingReviews tallies the reviews saved in a transaction that is not committed yet. Open review counts and load
+// are read outside the transaction, so flows that assign many reviewers in one transaction add these on top.
+type pendingReviews struct {
+	counts map[string]int
+	load   map[string]int
+}
+
+// withPendingReviews returns a context in which saveAssignments tallies the reviews it saves, and capacity and load
+// checks take them into account. It is meant for flows that pick reviewers for several pull requests in one
+// transaction; a tally already present in ctx is kept.
+func withPendingReviews(ctx context.Context) context.Context {
+	if pendingReviewsFrom(ctx) != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, pendingReviewsKey{}, &pendingReviews{counts: map[string]int{}, load: map[string]int{}})
+}
+
+// pendingReviewsFrom returns the tally set by withPendingReviews, or nil if there is none.
+func pendingReviewsFrom(ctx context.Context) *pendingReviews {
+	pending, _ := ctx.Value(pendingReviewsKey{}).(*pendingReviews)
+	return pending
+}
+
+// candidateTeams returns the teams to draw reviewers from: the home team first, then the author team
+// and, if the author team allows it, its fallback teams in configured order.
+func candidateTeams(homeTeam string, settings domain.TeamSettings) []string {
+	teams := []string{homeTeam}
+	if settings.TeamName != homeTeam {
+		teams = append(teams, settings.TeamName)
+	}
+	if !settings.AllowCrossTeamFallback {
+		return teams
+	}
+
+	for _, fallback := range settings.FallbackTeams {
+		seen := false
+		for _, t := range teams {
+			if t == fallback {
+				seen = true
+				break
+			}
+		}
+		if !seen {
+			teams = append(teams, fallback)
+		}
+	}
+	return teams
+}
+
+// pickForPR picks up to count new reviewers for a pull request. If the author team requires a senior and none is
+// assigned, a senior is picked first; when no senior is available the slots are filled anyway and the picks are
+// marked SeniorPending. Code owners of the changed files who are not yet represented among the assigned reviewers
+// come next, then users with a skill matching one of the pull request labels; remaining slots are filled from the
+// home team and, per the author team settings, its fallbacks. Users in exclude, users excluded by the author or by
+// exclusion rules and users who declined the pull request are never picked.
+func (s *PRService) pickForPR(
+	ctx context.Context,
+	pr domain.PullRequest,
+	settings domain.TeamSettings,
+	homeTeam string,
+	assigned []domain.User,
+	exclude []string,
+	count int,
+) ([]assignment, error) {
+	barred, err := s.barredReviewers(ctx, pr)
+	if err != nil {
+		return nil, err
+	}
+	exclude = append(append([]string(nil), exclude...), barred...)
+
+	var picked []assignment
+	seniorPending := false
+	if count > 0 && lacksSenior(settings, assigned) {
+		picked, err = s.pickSenior(ctx, settings, homeTeam, pr.AuthorID, exclude)
+		if err != nil {
+			return nil, err
+		}
+		if len(picked) > 0 {
+			picked[0].Reason = "senior required"
+			assigned = append(append([]domain.User(nil), assigned...), picked[0].User)
+			exclude = append(exclude, picked[0].User.ID)
+		} else {
+			seniorPending = true
+		}
+	}
+
+	if len(pr.ChangedFiles) > 0 {
+		rules, err := s.teamStorage.GetCodeOwnerRules(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get code owner rules: %w", err)
+		}
+
+		owners, err := s.pickOwners(ctx, settings, pr.AuthorID, matchingOwnerRules(rules, pr.ChangedFiles), assigned, exclude, count-len(picked))
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range owners {
+			exclude = append(exclude, a.User.ID)
+		}
+		picked = append(picked, owners...)
+	}
+
+	if len(pr.Labels) > 0 && count > len(picked) {
+		skilled, err := s.pickMatching(ctx, settings, homeTeam, pr.AuthorID, exclude, count-len(picked), func(u domain.User) bool {
+			return u.HasSkill(pr.Labels)
+		})
+		if err != nil {
+			return nil, err
+		}
+		for i := range skilled {
+			skilled[i].Reason = "skill match"
+			exclude = append(exclude, skilled[i].User.ID)
+		}
+		picked = append(picked, skilled...)
+	}
+
+	rest, err := s.pickFromTeams(ctx, candidateTeams(homeTeam, settings), settings.TeamName, pr.AuthorID, exclude, count-len(picked))
+	if err != nil {
+		return nil, err
+	}
+
+	picked = append(picked, rest...)
+	if seniorPending {
+		for i := range picked {
+			picked[i].SeniorPending = true
+		}
+	}
+	return picked, nil
+}
+
+// barredReviewers returns the users never picked automatically for a pull request: those excluded by the author,
+// those barred by exclusion rules and those who declined it.
+func (s *PRService) barredReviewers(ctx context.Context, pr domain.PullRequest) ([]string, error) {
+	declines, err := s.prStorage.GetDeclines(ctx, pr.ID)
+	if err != nil {
+		return nil, err
+	}
+	blocked, err := s.userStorage.GetBlockedReviewers(ctx, pr.AuthorID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	barred := append(append([]string(nil), pr.ExcludedReviewers...), blocked...)
+	for _, d := range declines {
+		barred = append(barred, d.UserID)
+	}
+	return barred, nil
+}
+
+// pickShadow picks a junior of the author team as a shadow reviewer. It returns nothing if no junior is available.
+func (s *PRService) pickShadow(ctx context.Context, pr domain.PullRequest, settings domain.TeamSettings, exclude []string) ([]assignment, error) {
+	barred, err := s.barredReviewers(ctx, pr)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.userStorage.GetActiveUsersByTeam(ctx, settings.TeamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get candidates: %w", err)
+	}
+
+	var juniors []domain.User
+	for _, u := range excludeUsers(members, append(exclude, barred...)...) {
+		if u.Role == domain.RoleJunior {
+			juniors = append(juniors, u)
+		}
+	}
+
+	users, err := s.pickReviewers(ctx, settings, pr.AuthorID, juniors, 1)
+	if err != nil || len(users) == 0 {
+		return nil, err
+	}
+	return []assignment{{User: users[0], Shadow: true, Strategy: string(s.strategyFor(settings)), Reason: "shadow reviewer"}}, nil
+}
+
+// pickSenior picks one senior or lead from the candidate teams of a pull request.
+func (s *PRService) pickSenior(ctx context.Context, settings domain.TeamSettings, homeTeam, authorID string, exclude []string) ([]assignment, error) {
+	return s.pickMatching(ctx, settings, homeTeam, authorID, exclude, 1, func(u domain.User) bool {
+		return u.Role.IsSenior()
+	})
+}
+
+// pickMatching fills up to count reviewer slots from the candidate teams of a pull request with users for which
+// match returns true.
+func (s *PRService) pickMatching(
+	ctx context.Context,
+	settings domain.TeamSettings,
+	homeTeam, authorID string,
+	exclude []string,
+	count int,
+	match func(domain.User) bool,
+) ([]assignment, error) {
+	teams := candidateTeams(homeTeam, settings)
+
+	exclude = append([]string(nil), exclude...)
+	for _, team := range teams {
+		members, err := s.userStorage.GetActiveUsersByTeam(ctx, team)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get candidates: %w", err)
+		}
+		for _, u := range members {
+			if !match(u) {
+				exclude = append(exclude, u.ID)
+			}
+		}
+	}
+
+	return s.pickFromTeams(ctx, teams, settings.TeamName, authorID, exclude, count)
+}
+
+// hasSenior reports whether any of the users is a senior or lead.
+func hasSenior(users []domain.User) bool {
+	for _, u := range users {
+		if u.Role.IsSenior() {
+			return true
+		}
+	}
+	return false
+}
+
+// lacksSenior reports whether the team requires a senior reviewer and none of the users is a senior or lead.
+func lacksSenior(settings domain.TeamSettings, users []domain.User) bool {
+	return settings.RequireSenior && !hasSenior(users)
+}
+
+// seniorPending reports whether any of the assignments was made without the senior the author team requires.
+func seniorPending(picked []assignment) bool {
+	for _, a := range picked {
+		if a.SeniorPending {
+			return true
+		}
+	}
+	return false
+}
+
+// requiredReviewers loads the reviewers of a pull request other than shadows.
+func (s *PRService) requiredReviewers(ctx context.Context, pr domain.PullRequest) ([]domain.User, error) {
+	var users []domain.User
+	for _, r := range pr.Reviewers {
+		if r.Shadow {
+			continue
+		}
+		u, err := s.userStorage.GetByID(ctx, r.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get reviewer: %w", err)
+		}
+		users = append(users, *u)
+	}
+	return users, nil
+}
+
+// assignedUsers returns the users of the assignments.
+func assignedUsers(picked []assignment) []domain.User {
+	users := make([]domain.User, 0, len(picked))
+	for _, a := range picked {
+		users = append(users, a.User)
+	}
+	return users
+}
+
+// pickFromTeams fills up to count reviewer slots from the given teams in order, using each team's own strategy.
+// Users listed in exclude are never picked.
+func (s *PRService) pickFromTeams(ctx context.Context, teams []string, authorTeam, authorID string, exclude []string, count int) ([]assignment, error) {
+	exclude = append([]string(nil), exclude...)
+
+	var picked []assignment
+	for _, team := range teams {
+		if len(picked) >= count {
+			break
+		}
+
+		settings, err := s.teamStorage.GetSettings(ctx, team)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get settings of team %s: %w", team, err)
+		}
+
+		candidates, err := s.userStorage.GetActiveUsersByTeam(ctx, team)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get candidates: %w", err)
+		}
+
+		users, err := s.pickReviewers(ctx, *settings, authorID, excludeUsers(candidates, exclude...), count-len(picked))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, u := range users {
+			a := assignment{User: u, Strategy: string(s.strategyFor(*settings))}
+			if team != authorTeam {
+				a.FallbackTeam = team
+			}
+			picked = append(picked, a)
+			exclude = append(exclude, u.ID)
+		}
+	}
+
+	return picked, nil
+}
+
+// pickReviewers selects up to count reviewers for a pull request of the author from candidates, using the strategy
+// and pairing window configured for the team. A round robin rotation continues from the team's stored cursor, which
+// is advanced once past everyone picked.
+func (s *PRService) pickReviewers(ctx context.Context, settings domain.TeamSettings, authorID string, candidates []domain.User, count int) ([]domain.User, error) {
+	candidates, openReviews, err := s.withinCapacity(ctx, candidates)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	strategy := s.strategyFor(settings)
+	selector := s.selectors[strategy]
+
+	var cursor string
+	if strategy == domain.StrategyRoundRobin {
+		if cursor, err = s.teamStorage.GetRoundRobinCursor(ctx, settings.TeamName); err != nil {
+			return nil, err
+		}
+	}
+
+	pairings := map[string]int{}
+	if settings.PairingWindowDays > 0 {
+		since := time.Now().AddDate(0, 0, -settings.PairingWindowDays)
+		pairings, err = s.prStorage.GetRecentPairings(ctx, authorID, since)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	load, err := s.reviewLoad(ctx, candidates)
+	if err != nil {
+		return nil, err
+	}
+	holidays, err := s.teamHolidays(ctx, candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reviewers in working hours come first; the others only fill the slots that are left.
+	working, off := splitByWorkingTime(candidates, holidays, time.Now())
+	picked := selector.Select(ReviewerPool{
+		TeamName:       settings.TeamName,
+		Candidates:     working,
+		OpenReviews:    openReviews,
+		Load:           load,
+		RecentPairings: pairings,
+		LastPicked:     cursor,
+	}, count)
+	if len(picked) < count && len(off) > 0 {
+		picked = append(picked, selector.Select(ReviewerPool{
+			TeamName:       settings.TeamName,
+			Candidates:     off,
+			OpenReviews:    openReviews,
+			Load:           load,
+			RecentPairings: pairings,
+			LastPicked:     cursor,
+		}, count-len(picked))...)
+	}
+
+	if strategy == domain.StrategyRoundRobin && len(picked) > 0 {
+		if err = s.teamStorage.SetRoundRobinCursor(ctx, settings.TeamName, advanceRotation(cursor, picked)); err != nil {
+			return nil, err
+		}
+	}
+	return picked, nil
+}
+
+// strategyFor returns the reviewer strategy used for the team: its own one if registered, the default otherwise.
+func (s *PRService) strategyFor(settings domain.TeamSettings) domain.ReviewerStrategy {
+	if _, ok := s.selectors[settings.ReviewerStrategy]; ok {
+		return settings.ReviewerStrategy
+	}
+	return domain.DefaultReviewerStrategy
+}
+
+// reviewLoad sums, for each candidate, the size weights of the OPEN pull requests they are reviewing.
+func (s *PRService) reviewLoad(ctx context.Context, candidates []domain.User) (map[string]int, error) {
+	load := make(map[string]int, len(candidates))
+	loaded := make(map[string]bool)
+	for _, c := range candidates {
+		if loaded[c.TeamName] {
+			continue
+		}
+		loaded[c.TeamName] = true
+
+		sizes, err := s.prStorage.GetOpenReviewSizesByTeam(ctx, c.TeamName)
+		if err != nil {
+			return nil, err
+		}
+		for userID, counts := range sizes {
+			for size, n := range counts {
+				load[userID] += n * size.Weight()
+			}
+		}
+	}
+	if pending := pendingReviewsFrom(ctx); pending != nil {
+		for _, c := range candidates {
+			load[c.ID] += pending.load[c.ID]
+		}
+	}
+	return load, nil
+}
+
+// withinCapacity drops candidates already reviewing as many OPEN pull requests as they may: their own limit if set,
+// otherwise the default of their team. It also returns the open review counts of all candidates.
+func (s *PRService) withinCapacity(ctx context.Context, candidates []domain.User) ([]domain.User, map[string]int, error) {
+	// candidates may come from several teams, e.g. when picking code owners
+	openReviews := make(map[string]int)
+	teamLimits := make(map[string]int)
+	loaded := make(map[string]bool)
+	for _, c := range candidates {
+		if loaded[c.TeamName] {
+			continue
+		}
+		loaded[c.TeamName] = true
+
+		counts, err := s.prStorage.GetOpenReviewCountsByTeam(ctx, c.TeamName)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get open review counts: %w", err)
+		}
+		for id, n := range counts {
+			openReviews[id] = n
+		}
+		if pending := pendingReviewsFrom(ctx); pending != nil {
+			for id := range counts {
+				openReviews[id] += pending.counts[id]
+			}
+		}
+
+		settings, err := s.teamStorage.GetSettings(ctx, c.TeamName)
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, fmt.Errorf("failed to get team settings: %w", err)
+		}
+		if settings != nil {
+			teamLimits[c.TeamName] = settings.MaxOpenReviews
+		}
+	}
+
+	var available []domain.User
+	for _, c := range candidates {
+		limit := teamLimits[c.TeamName]
+		if c.MaxOpenReviews != nil {
+			limit = *c.MaxOpenReviews
+		}
+		if limit > 0 && openReviews[c.ID] >= limit {
+			continue
+		}
+		available = append(available, c)
+	}
+	return available, openReviews, nil
+}
+
+// hasCandidatesAtCapacity reports whether any active member of the teams, apart from the excluded users,
+// is left out of selection only because they are at review capacity.
+func (s *PRService) hasCandidatesAtCapacity(ctx context.Context, teams []string, exclude []string) (bool, error) {
+	for _, team := range teams {
+		members, err := s.userStorage.GetActiveUsersByTeam(ctx, team)
+		if err != nil {
+			return false, fmt.Errorf("failed to get candidates: %w", err)
+		}
+		candidates := excludeUsers(members, exclude...)
+
+		available, _, err := s.withinCapacity(ctx, candidates)
+		if err != nil {
+			return false, err
+		}
+		if len(available) < len(candidates) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// saveAssignments stores picked reviewers of a pull request and records them in its history with the given reason.
+// Reviewers other than shadows get a deadline if the author team has a review SLA. The reviews are added to the
+// pending reviews tally of ctx, if any. If a required senior was not available the pull request is marked as
+// needing reviewers, so the pending reviewer worker adds the senior later.
+func (s *PRService) saveAssignments(
+	ctx context.Context,
+	executor storage.QueryExecutor,
+	pr domain.PullRequest,
+	picked []assignment,
+	reason string,
+) error {
+	if len(picked) == 0 {
+		return nil
+	}
+	slaHours, err := s.reviewSLAHours(ctx, pr.AuthorID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+
+	for _, a := range picked {
+		switch {
+		case a.Shadow:
+			err = s.prStorage.SaveShadowReviewer(ctx, executor, pr.ID, a.User.ID)
+		case a.FallbackTeam != "":
+			err = s.prStorage.SaveFallbackReviewer(ctx, executor, pr.ID, a.User.ID, a.FallbackTeam)
+		default:
+			err = s.prStorage.SaveReviewer(ctx, executor, pr.ID, a.User.ID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to save reviewer: %w", err)
+		}
+		if pending := pendingReviewsFrom(ctx); pending != nil {
+			pending.counts[a.User.ID]++
+			pending.load[a.User.ID] += pr.Size.Weight()
+		}
+
+		if slaHours > 0 && !a.Shadow {
+			due, err := s.reviewDeadline(ctx, a.User, now, slaHours)
+			if err != nil {
+				return err
+			}
+			if err = s.prStorage.SetReviewDueAt(ctx, executor, pr.ID, a.User.ID, due); err != nil {
+				return err
+			}
+		}
+
+		event := domain.PREvent{
+			PRID:       pr.ID,
+			Type:       domain.PREventAssigned,
+			ReviewerID: a.User.ID,
+			Reason:     reason,
+			Strategy:   a.Strategy,
+			Shadow:     a.Shadow,
+		}
+		if a.Replaces != "" {
+			event.Type = domain.PREventReassigned
+			event.PreviousReviewerID = a.Replaces
+		}
+		if a.Reason != "" {
+			event.Reason += " (" + a.Reason + ")"
+		}
+		if err = s.recordEvent(ctx, executor, event); err != nil {
+			return err
+		}
+	}
+
+	if seniorPending(picked) {
+		return s.prStorage.SetNeedsReviewers(ctx, executor, pr.ID, true)
+	}
+	return nil
+}
+
+// assignInitialReviewers picks and saves the reviewers of a pull request that has none yet,
+// following the settings of the author's team. Eligible requested reviewers come first and the remaining slots
+// are filled by the usual rules; rejected requests are reported in pr.RejectedReviewers. A junior shadow reviewer
+// is added on top if the team asks for one. The pull request is updated with the new reviewers and is marked as
+// needing reviewers when fewer than reviewer_count were found or the required senior is missing.
+func (s *PRService) assignInitialReviewers(ctx context.Context, executor storage.QueryExecutor, pr *domain.PullRequest, authorTeam string) error {
+	settings, err := s.teamStorage.GetSettings(ctx, authorTeam)
+	if err != nil {
+		return fmt.Errorf("failed to get team settings: %w", err)
+	}
+
+	picked, rejected, err := s.requestedAssignments(ctx, *pr, *settings, authorTeam)
+	if err != nil {
+		return err
+	}
+	assigned := make([]domain.User, 0, len(picked))
+	exclude := []string{pr.AuthorID}
+	for _, a := range picked {
+		assigned = append(assigned, a.User)
+		exclude = append(exclude, a.User.ID)
+	}
+
+	reviewerCount := settings.ReviewerCountFor(pr.Size)
+	rest, err := s.pickForPR(ctx, *pr, *settings, authorTeam, assigned, exclude, reviewerCount-len(picked))
+	if err != nil {
+		return err
+	}
+	picked = append(picked, rest...)
+	pr.RejectedReviewers = rejected
+	if len(picked) == 0 || len(picked) < settings.MinReviewers {
+		full, err := s.hasCandidatesAtCapacity(ctx, candidateTeams(authorTeam, *settings), []string{pr.AuthorID})
+		if err != nil {
+			return err
+		}
+		switch {
+		case full && settings.QueueWhenFull:
+			// the PR is created anyway; the pending reviewer worker fills it later
+		case full:
+			return conflict(ErrCodeCapacityExceeded, "all candidate reviewers are at capacity")
+		case len(picked) < settings.MinReviewers:
+			return conflict(ErrCodeNoCandidate, "not enough active reviewers in team")
+		}
+	}
+
+	required := len(picked)
+	if settings.ShadowReviewer {
+		exclude = []string{pr.AuthorID}
+		for _, a := range picked {
+			exclude = append(exclude, a.User.ID)
+		}
+		shadow, err := s.pickShadow(ctx, *pr, *settings, exclude)
+		if err != nil {
+			return err
+		}
+		picked = append(picked, shadow...)
+	}
+
+	if err = s.saveAssignments(ctx, executor, *pr, picked, "pull request opened"); err != nil {
+		return err
+	}
+	pr.Reviewers = newAssignedReviewers(picked)
+
+	if required < reviewerCount {
+		if err = s.prStorage.SetNeedsReviewers(ctx, executor, pr.ID, true); err != nil {
+			return err
+		}
+	}
+	pr.NeedsReviewers = required < reviewerCount || seniorPending(picked)
+	return nil
+}
+
+// newAssignedReviewers describes freshly saved assignments as pending reviews.
+func newAssignedReviewers(picked []assignment) []domain.AssignedReviewer {
+	now := time.Now()
+	reviewers := make([]domain.AssignedReviewer, 0, len(picked))
+	for _, a := range picked {
+		reviewers = append(reviewers, domain.AssignedReviewer{
+			UserID:       a.User.ID,
+			State:        domain.ReviewStatePending,
+			AssignedAt:   &now,
+			FallbackTeam: a.FallbackTeam,
+			Shadow:       a.Shadow,
+		})
+	}
+	return reviewers
+}
+
+// pickReplacement picks a reviewer to take over oldUser's review of a pull request: from oldUser's team first,
+// then the author team and its fallbacks, keeping code owners represented. The author, current reviewers
+// and users in exclude are never picked. The picked reviewer is marked as replacing oldUser.
+func (s *PRService) pickReplacement(ctx context.Context, pr domain.PullRequest, oldUser domain.User, exclude []string) ([]assignment, error) {
+	author, err := s.userStorage.GetByID(ctx, pr.AuthorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get author: %w", err)
+	}
+	settings, err := s.teamStorage.GetSettings(ctx, author.TeamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team settings: %w", err)
+	}
+
+	reviewers, err := s.requiredReviewers(ctx, pr)
+	if err != nil {
+		return nil, err
+	}
+	remaining := excludeUsers(reviewers, oldUser.ID)
+
+	exclude = append(append([]string{pr.AuthorID, oldUser.ID}, exclude...), pr.ReviewerIDs()...)
+	picked, err := s.pickForPR(ctx, pr, *settings, oldUser.TeamName, remaining, exclude, 1)
+	if err != nil {
+		return nil, err
+	}
+	for i := range picked {
+		picked[i].Replaces = oldUser.ID
+	}
+	return picked, nil
+}
+
+// reassignOpenReviews moves every review of the user on an OPEN pull request to a replacement.
+// Reviews without a replacement candidate are removed and reported as unfilled; shadow reviews are just removed.
+// Replacements already picked count towards the load and capacity of later picks.
+func (s *PRService) reassignOpenReviews(ctx context.Context, executor storage.QueryExecutor, user domain.User) (*domain.ReassignmentReport, error) {
+	ctx = withPendingReviews(ctx)
+	prs, err := s.prStorage.GetByReviewerID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.ReassignmentReport{
+		Reassigned: []domain.ReviewerReplacement{},
+		Unfilled:   []domain.ReviewerReplacement{},
+	}
+	for _, short := range prs {
+		if short.Status != domain.PRStatusOpen {
+			continue
+		}
+
+		pr, err := s.prStorage.GetByID(ctx, short.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pr: %w", err)
+		}
+		if pr.IsShadowReviewer(user.ID) {
+			if err = s.prStorage.DeleteReviewer(ctx, executor, pr.ID, user.ID); err != nil {
+				return nil, err
+			}
+			if err = s.recordRemoval(ctx, executor, pr.ID, user.ID, "reviewer deactivated", true); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		picked, err := s.pickReplacement(ctx, *pr, user, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = s.prStorage.DeleteReviewer(ctx, executor, pr.ID, user.ID); err != nil {
+			return nil, err
+		}
+		if err = s.saveAssignments(ctx, executor, *pr, picked, "reviewer deactivated"); err != nil {
+			return nil, err
+		}
+
+		replacement := domain.ReviewerReplacement{PRID: pr.ID, OldReviewerID: user.ID}
+		if len(picked) == 0 {
+			if err = s.recordRemoval(ctx, executor, pr.ID, user.ID, "reviewer deactivated", false); err != nil {
+				return nil, err
+			}
+			if err = s.prStorage.SetNeedsReviewers(ctx, executor, pr.ID, true); err != nil {
+				return nil, err
+			}
+			report.Unfilled = append(report.Unfilled, replacement)
+			continue
+		}
+		replacement.NewReviewerID = picked[0].User.ID
+		report.Reassigned = append(report.Reassigned, replacement)
+	}
+
+	return report, nil
+}
+
+// backfillTeamReviews replaces the reviews that members of a deactivated team held on a pull request.
+// The reviews are expected to be already removed through the executor; members are never picked as replacements.
+// Callers backfilling several pull requests in one transaction should tally the picks with withPendingReviews.
+func (s *PRService) backfillTeamReviews(ctx context.Context, executor storage.QueryExecutor, prID string, members []domain.User) (*domain.PRReassignment, error) {
+	pr, err := s.prStorage.GetByID(ctx, prID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pr: %w", err)
+	}
+
+	memberIDs := make([]string, 0, len(members))
+	byID := make(map[string]domain.User, len(members))
+	for _, u := range members {
+		memberIDs = append(memberIDs, u.ID)
+		byID[u.ID] = u
+	}
+
+	var removed []domain.User
+	kept := make([]domain.AssignedReviewer, 0, len(pr.Reviewers))
+	for _, r := range pr.Reviewers {
+		if u, ok := byID[r.UserID]; ok {
+			if !r.Shadow {
+				removed = append(removed, u)
+			} else if err = s.recordRemoval(ctx, executor, pr.ID, u.ID, "team deactivated", true); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		kept = append(kept, r)
+	}
+	pr.Reviewers = kept
+
+	result := &domain.PRReassignment{
+		PRID:       pr.ID,
+		Reassigned: []domain.ReviewerReplacement{},
+		Unfilled:   []domain.ReviewerReplacement{},
+	}
+	for _, oldUser := range removed {
+		picked, err := s.pickReplacement(ctx, *pr, oldUser, memberIDs)
+		if err != nil {
+			return nil, err
+		}
+		if err = s.saveAssignments(ctx, executor, *pr, picked, "team deactivated"); err != nil {
+			return nil, err
+		}
+
+		replacement := domain.ReviewerReplacement{PRID: pr.ID, OldReviewerID: oldUser.ID}
+		if len(picked) == 0 {
+			if err = s.recordRemoval(ctx, executor, pr.ID, oldUser.ID, "team deactivated", false); err != nil {
+				return nil, err
+			}
+			if err = s.prStorage.SetNeedsReviewers(ctx, executor, pr.ID, true); err != nil {
+				return nil, err
+			}
+			result.Unfilled = append(result.Unfilled, replacement)
+			continue
+		}
+		replacement.NewReviewerID = picked[0].User.ID
+		result.Reassigned = append(result.Reassigned, replacement)
+		pr.Reviewers = append(pr.Reviewers, newAssignedReviewers(picked)...)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/neizhmak/avito-review-service/internal/domain"
+	"github.com/neizhmak/avito-review-service/internal/storage"
+)
+
+// AddUnavailability schedules a period during which the user is not picked as a reviewer.
+// Reviews already assigned to the user are left in place.
+func (s *PRService) AddUnavailability(ctx context.Context, period domain.Unavailability) (*domain.Unavailability, error) {
+	if _, err := s.userStorage.GetByID(ctx, period.UserID); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, notFound("user not found")
+		}
+		return nil, err
+	}
+
+	if period.StartsAt.IsZero() || period.EndsAt.IsZero() {
+		return nil, newServiceError(ErrCodeInvalidPeriod, "starts_at and ends_at are required")
+	}
+	if !period.EndsAt.After(period.StartsAt) {
+		return nil, newServiceError(ErrCodeInvalidPeriod, "ends_at must be after starts_at")
+	}
+	period.Reason = strings.TrimSpace(period.Reason)
+
+	id, err := s.userStorage.AddUnavailability(ctx, period)
+	if err != nil {
+		return nil, err
+	}
+	period.ID = id
+
+	return &period, nil
+}
+
+// GetUnavailability lists the current and upcoming unavailability periods of a user.
+func (s *PRService) GetUnavailability(ctx context.Context, userID string) ([]domain.Unavailability, error) {
+	if _, err := s.userStorage.GetByID(ctx, userID); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, notFound("user not found")
+		}
+		return nil, err
+	}
+	return s.userStorage.GetUnavailability(ctx, userID)
+}
+
+// DeleteUnavailability cancels an unavailability period of a user.
+func (s *PRService) DeleteUnavailability(ctx context.Context, userID string, id int64) error {
+	if err := s.userStorage.DeleteUnavailability(ctx, userID, id); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return notFound("unavailability period not found")
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/neizhmak/avito-review-service/internal/domain"
+	"github.com/neizhmak/avito-review-service/internal/storage"
+)
+
+// workCalendar describes when a user works: their working hours on working days, in their own time zone,
+// except on the holidays of their team.
+type workCalendar struct {
+	loc       *time.Location
+	startHour int
+	endHour   int
+	days      map[time.Weekday]bool
+	holidays  map[string]bool
+}
+
+// newWorkCalendar builds the calendar of a user from their schedule and the holidays of their team.
+// Missing schedule fields fall back to the defaults and an unknown time zone to UTC.
+func newWorkCalendar(user domain.User, holidays []domain.Holiday) workCalendar {
+	user.ApplyScheduleDefaults()
+
+	loc, err := time.LoadLocation(user.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+	c := workCalendar{
+		loc:       loc,
+		startHour: user.WorkStartHour,
+		endHour:   user.WorkEndHour,
+		days:      make(map[time.Weekday]bool, len(user.WorkDays)),
+		holidays:  make(map[string]bool, len(holidays)),
+	}
+	for _, d := range user.WorkDays {
+		c.days[time.Weekday(d%7)] = true
+	}
+	for _, h := range holidays {
+		c.holidays[h.Date] = true
+	}
+	return c
+}
+
+// isWorkday reports whether the local day of t is a working day that is not a holiday.
+func (c workCalendar) isWorkday(t time.Time) bool {
+	t = t.In(c.loc)
+	return c.days[t.Weekday()] && !c.holidays[t.Format(time.DateOnly)]
+}
+
+// isWorkingTime reports whether t falls into working hours.
+func (c workCalendar) isWorkingTime(t time.Time) bool {
+	if !c.isWorkday(t) {
+		return false
+	}
+	hour := t.In(c.loc).Hour()
+	return hour >= c.startHour && hour < c.endHour
+}
+
+// addWorkingHours returns the moment the given number of working hours have passed since start.
+// Time outside working hours does not count. A calendar without a single working day never reaches the
+// deadline, so the search gives up after a year and counts the hours as plain time.
+func (c workCalendar) addWorkingHours(start time.Time, hours int) time.Time {
+	remaining := time.Duration(hours) * time.Hour
+	t := start.In(c.loc)
+	for i := 0; i < 366; i++ {
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, c.loc)
+		open := time.Date(t.Year(), t.Month(), t.Day(), c.startHour, 0, 0, 0, c.loc)
+		closing := time.Date(t.Year(), t.Month(), t.Day(), c.endHour, 0, 0, 0, c.loc)
+
+		if !c.isWorkday(day) || !t.Before(closing) {
+			next := day.AddDate(0, 0, 1)
+			t = time.Date(next.Year(), next.Month(), next.Day(), c.startHour, 0, 0, 0, c.loc)
+			continue
+		}
+		if t.Before(open) {
+			t = open
+		}
+
+		left := closing.Sub(t)
+		if remaining <= left {
+			return t.Add(remaining).UTC()
+		}
+		remaining -= left
+		t = closing
+	}
+	return start.Add(time.Duration(hours) * time.Hour).UTC()
+}
+
+// teamHolidays loads the holidays of every team the users belong to, keyed by team name.
+func (s *PRService) teamHolidays(ctx context.Context, users []domain.User) (map[string][]domain.Holiday, error) {
+	holidays := make(map[string][]domain.Holiday)
+	for _, u := range users {
+		if _, ok := holidays[u.TeamName]; ok {
+			continue
+		}
+		teamHolidays, err := s.teamStorage.GetHolidays(ctx, u.TeamName)
+		if err != nil {
+			return nil, err
+		}
+		holidays[u.TeamName] = teamHolidays
+	}
+	return holidays, nil
+}
+
+// splitByWorkingTime separates the users who are in working hours at the given moment from those who are not,
+// keeping the original order in both groups.
+func splitByWorkingTime(users []domain.User, holidays map[string][]domain.Holiday, at time.Time) (working, off []domain.User) {
+	for _, u := range users {
+		if newWorkCalendar(u, holidays[u.TeamName]).isWorkingTime(at) {
+			working = append(working, u)
+		} else {
+			off = append(off, u)
+		}
+	}
+	return working, off
+}
+
+// validateSchedule checks the working schedule of a user: a known time zone, hours within the day with the start
+// before the end, and ISO weekday numbers.
+func validateSchedule(user domain.User) error {
+	if _, err := time.LoadLocation(user.TimeZone); err != nil {
+		return newServiceError(ErrCodeInvalidSettings, "unknown time_zone "+user.TimeZone)
+	}
+	if user.WorkStartHour < 0 || user.WorkEndHour > 24 || user.WorkStartHour >= user.WorkEndHour {
+		return newServiceError(ErrCodeInvalidSettings, "work hours must satisfy 0 <= work_start_hour < work_end_hour <= 24")
+	}
+	for _, d := range user.WorkDays {
+		if d < 1 || d > 7 {
+			return newServiceError(ErrCodeInvalidSettings, fmt.Sprintf("work_days must be between 1 and 7, got %d", d))
+		}
+	}
+	return nil
+}
+
+// GetHolidays lists the holidays of a team.
+func (s *PRService) GetHolidays(ctx context.Context, teamName string) ([]domain.Holiday, error) {
+	if _, err := s.teamStorage.GetByName(ctx, teamName); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, notFound("team not found")
+		}
+		return nil, err
+	}
+	return s.teamStorage.GetHolidays(ctx, teamName)
+}
+
+// AddHoliday adds a day off to the team calendar. Adding a date twice renames the holiday.
+func (s *PRService) AddHoliday(ctx context.Context, holiday domain.Holiday) (*domain.Holiday, error) {
+	if _, err := time.Parse(time.DateOnly, holiday.Date); err != nil {
+		return nil, newServiceError(ErrCodeInvalidSettings, "date must be formatted as YYYY-MM-DD")
+	}
+	if _, err := s.teamStorage.GetByName(ctx, holiday.TeamName); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, notFound("team not found")
+		}
+		return nil, err
+	}
+
+	if err := s.teamStorage.SaveHoliday(ctx, holiday); err != nil {
+		return nil, err
+	}
+	return &holiday, nil
+}
+
+// DeleteHoliday removes a day off from the team calendar.
+func (s *PRService) DeleteHoliday(ctx context.Context, teamName, date string) error {
+	if err := s.teamStorage.DeleteHoliday(ctx, teamName, date); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return notFound("holiday not found")
+		}
+		return err
+	}
+	return nil
+}
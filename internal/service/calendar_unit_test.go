@@ -0,0 +1,125 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/neizhmak/avito-review-service/internal/domain"
+)
+
+func TestAddWorkingHours(t *testing.T) {
+	at := func(day, hour, minute int) time.Time {
+		// December 2025: the 8th is a Monday
+		return time.Date(2025, time.December, day, hour, minute, 0, 0, time.UTC)
+	}
+	calendar := newWorkCalendar(domain.User{}, nil)
+
+	tests := []struct {
+		name  string
+		start time.Time
+		hours int
+		want  time.Time
+	}{
+		{name: "within a day", start: at(8, 10, 0), hours: 3, want: at(8, 13, 0)},
+		{name: "spills to next day", start: at(8, 16, 30), hours: 2, want: at(9, 9, 30)},
+		{name: "before opening", start: at(8, 6, 0), hours: 1, want: at(8, 10, 0)},
+		{name: "after closing", start: at(8, 20, 0), hours: 1, want: at(9, 10, 0)},
+		{name: "friday evening to monday", start: at(12, 17, 0), hours: 2, want: at(15, 10, 0)},
+		{name: "weekend start", start: at(13, 12, 0), hours: 9, want: at(15, 18, 0)},
+		{name: "three working days", start: at(8, 9, 0), hours: 24, want: at(10, 15, 0)},
+		{name: "zero", start: at(8, 12, 0), hours: 0, want: at(8, 12, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := calendar.addWorkingHours(tt.start, tt.hours); !got.Equal(tt.want) {
+				t.Fatalf("want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAddWorkingHours_Schedule(t *testing.T) {
+	at := func(day, hour int) time.Time {
+		return time.Date(2025, time.December, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name     string
+		user     domain.User
+		holidays []domain.Holiday
+		start    time.Time
+		hours    int
+		want     time.Time
+	}{
+		{
+			// Novosibirsk is UTC+7: 09:00-18:00 local is 02:00-11:00 UTC
+			name:  "time zone",
+			user:  domain.User{TimeZone: "Asia/Novosibirsk"},
+			start: at(8, 10),
+			hours: 3,
+			want:  at(9, 4),
+		},
+		{
+			name:  "custom hours",
+			user:  domain.User{WorkStartHour: 12, WorkEndHour: 16},
+			start: at(8, 9),
+			hours: 5,
+			want:  at(9, 13),
+		},
+		{
+			name:  "custom days",
+			user:  domain.User{WorkDays: []int{1, 3}},
+			start: at(8, 17),
+			hours: 2,
+			want:  at(10, 10),
+		},
+		{
+			name:     "holiday",
+			holidays: []domain.Holiday{{Date: "2025-12-09"}},
+			start:    at(8, 17),
+			hours:    2,
+			want:     at(10, 10),
+		},
+		{
+			name:  "unknown time zone falls back to UTC",
+			user:  domain.User{TimeZone: "Mars/Olympus"},
+			start: at(8, 10),
+			hours: 1,
+			want:  at(8, 11),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newWorkCalendar(tt.user, tt.holidays).addWorkingHours(tt.start, tt.hours)
+			if !got.Equal(tt.want) {
+				t.Fatalf("want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSplitByWorkingTime(t *testing.T) {
+	users := []domain.User{
+		{ID: "moscow", TeamName: "backend", TimeZone: "Europe/Moscow"},
+		{ID: "novosibirsk", TeamName: "backend", TimeZone: "Asia/Novosibirsk"},
+		{ID: "yerevan", TeamName: "mobile", TimeZone: "Asia/Yerevan"},
+	}
+	holidays := map[string][]domain.Holiday{"mobile": {{TeamName: "mobile", Date: "2025-12-08"}}}
+
+	// Monday 07:00 UTC: 10:00 in Moscow, 14:00 in Novosibirsk, a holiday for the Yerevan team
+	working, off := splitByWorkingTime(users, holidays, time.Date(2025, time.December, 8, 7, 0, 0, 0, time.UTC))
+	if ids := userIDs(working); len(ids) != 2 || ids[0] != "moscow" || ids[1] != "novosibirsk" {
+		t.Fatalf("unexpected working users %v", ids)
+	}
+	if ids := userIDs(off); len(ids) != 1 || ids[0] != "yerevan" {
+		t.Fatalf("unexpected off users %v", ids)
+	}
+
+	// Monday 12:00 UTC: 15:00 in Moscow, 19:00 in Novosibirsk
+	working, _ = splitByWorkingTime(users[:2], nil, time.Date(2025, time.December, 8, 12, 0, 0, 0, time.UTC))
+	if ids := userIDs(working); len(ids) != 1 || ids[0] != "moscow" {
+		t.Fatalf("unexpected working users %v", ids)
+	}
+}
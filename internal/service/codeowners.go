@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/neizhmak/avito-review-service/internal/domain"
+	"github.com/neizhmak/avito-review-service/internal/storage"
+)
+
+// GetCodeOwnerRules retrieves the configured code owner rules.
+func (s *PRService) GetCodeOwnerRules(ctx context.Context) ([]domain.CodeOwnerRule, error) {
+	return s.teamStorage.GetCodeOwnerRules(ctx)
+}
+
+// SetCodeOwnerRules validates and replaces all code owner rules. As in CODEOWNERS, the last matching rule wins.
+func (s *PRService) SetCodeOwnerRules(ctx context.Context, rules []domain.CodeOwnerRule) ([]domain.CodeOwnerRule, error) {
+	for _, r := range rules {
+		if strings.TrimSpace(r.Pattern) == "" {
+			return nil, newServiceError(ErrCodeInvalidRule, "pattern is required")
+		}
+		if _, err := path.Match(strings.Trim(r.Pattern, "/"), ""); err != nil {
+			return nil, newServiceError(ErrCodeInvalidRule, "invalid pattern "+r.Pattern)
+		}
+		if len(r.Users) == 0 && len(r.Teams) == 0 {
+			return nil, newServiceError(ErrCodeInvalidRule, "rule "+r.Pattern+" has no owners")
+		}
+		for _, userID := range r.Users {
+			if _, err := s.userStorage.GetByID(ctx, userID); err != nil {
+				if errors.Is(err, storage.ErrNotFound) {
+					return nil, newServiceError(ErrCodeInvalidRule, "owner user "+userID+" not found")
+				}
+				return nil, err
+			}
+		}
+		for _, teamName := range r.Teams {
+			if _, err := s.teamStorage.GetByName(ctx, teamName); err != nil {
+				if errors.Is(err, storage.ErrNotFound) {
+					return nil, newServiceError(ErrCodeInvalidRule, "owner team "+teamName+" not found")
+				}
+				return nil, err
+			}
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err = s.teamStorage.ReplaceCodeOwnerRules(ctx, tx, rules); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit tx: %w", err)
+	}
+
+	return rules, nil
+}
+
+// matchingOwnerRules returns the distinct rules owning at least one of the files.
+// For each file only the last matching rule applies.
+func matchingOwnerRules(rules []domain.CodeOwnerRule, files []string) []domain.CodeOwnerRule {
+	matched := make(map[int]bool)
+	var result []domain.CodeOwnerRule
+	for _, file := range files {
+		for i := len(rules) - 1; i >= 0; i-- {
+			if !matchOwnerPattern(rules[i].Pattern, file) {
+				continue
+			}
+			if !matched[i] {
+				matched[i] = true
+				result = append(result, rules[i])
+			}
+			break
+		}
+	}
+	return result
+}
+
+// matchOwnerPattern reports whether a file path matches a CODEOWNERS-style pattern.
+// A leading slash anchors the pattern to the repository root; a pattern without other slashes matches at any depth;
+// "**" spans any number of directories; a pattern naming a directory matches everything below it.
+func matchOwnerPattern(pattern, file string) bool {
+	file = strings.TrimPrefix(strings.TrimPrefix(file, "./"), "/")
+
+	anchored := strings.HasPrefix(pattern, "/")
+	pattern = strings.Trim(pattern, "/")
+	if pattern == "" {
+		return false
+	}
+	if !anchored && !strings.Contains(pattern, "/") {
+		pattern = "**/" + pattern
+	}
+
+	patternSegs := strings.Split(pattern, "/")
+	fileSegs := strings.Split(file, "/")
+
+	return matchSegments(patternSegs, fileSegs) || matchSegments(append(patternSegs, "**"), fileSegs)
+}
+
+// matchSegments matches path segments, treating "**" as zero or more segments.
+func matchSegments(pattern, file []string) bool {
+	if len(pattern) == 0 {
+		return len(file) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(file); i++ {
+			if matchSegments(pattern[1:], file[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(file) == 0 {
+		return false
+	}
+	ok, err := path.Match(pattern[0], file[0])
+	if err != nil || !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], file[1:])
+}
+
+// isOwner reports whether the user is one of the rule's owners, directly or through their team.
+func isOwner(rule domain.CodeOwnerRule, user domain.User) bool {
+	for _, id := range rule.Users {
+		if id == user.ID {
+			return true
+		}
+	}
+	for _, team := range rule.Teams {
+		if team == user.TeamName {
+			return true
+		}
+	}
+	return false
+}
+
+// ownerCandidates returns the active owners of a rule who are not currently unavailable.
+func (s *PRService) ownerCandidates(ctx context.Context, rule domain.CodeOwnerRule) ([]domain.User, error) {
+	var candidates []domain.User
+	seen := make(map[string]bool)
+
+	for _, team := range rule.Teams {
+		members, err := s.userStorage.GetActiveUsersByTeam(ctx, team)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get owner team members: %w", err)
+		}
+		for _, u := range members {
+			if !seen[u.ID] {
+				seen[u.ID] = true
+				candidates = append(candidates, u)
+			}
+		}
+	}
+
+	for _, id := range rule.Users {
+		if seen[id] {
+			continue
+		}
+		u, err := s.userStorage.GetByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get owner: %w", err)
+		}
+		if !u.IsActive {
+			continue
+		}
+		unavailable, err := s.userStorage.IsUnavailable(ctx, id, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		if !unavailable {
+			seen[id] = true
+			candidates = append(candidates, *u)
+		}
+	}
+
+	return candidates, nil
+}
+
+// pickOwners picks one reviewer for every rule not yet covered by the assigned reviewers, up to limit picks.
+// Users in exclude are never picked.
+func (s *PRService) pickOwners(
+	ctx context.Context,
+	settings domain.TeamSettings,
+	authorID string,
+	rules []domain.CodeOwnerRule,
+	assigned []domain.User,
+	exclude []string,
+	limit int,
+) ([]assignment, error) {
+	exclude = append([]string(nil), exclude...)
+	assigned = append([]domain.User(nil), assigned...)
+
+	var picked []assignment
+	for _, rule := range rules {
+		if len(picked) >= limit {
+			break
+		}
+
+		covered := false
+		for _, u := range assigned {
+			if isOwner(rule, u) {
+				covered = true
+				break
+			}
+		}
+		if covered {
+			continue
+		}
+
+		candidates, err := s.ownerCandidates(ctx, rule)
+		if err != nil {
+			return nil, err
+		}
+
+		users, err := s.pickReviewers(ctx, settings, authorID, excludeUsers(candidates, exclude...), 1)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range users {
+			picked = append(picked, assignment{User: u, Strategy: string(s.strategyFor(settings)), Reason: "code owner of " + rule.Pattern})
+			assigned = append(assigned, u)
+			exclude = append(exclude, u.ID)
+		}
+	}
+
+	return picked, nil
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/neizhmak/avito-review-service/internal/domain"
+)
+
+func TestMatchOwnerPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		file    string
+		want    bool
+	}{
+		{pattern: "*.go", file: "main.go", want: true},
+		{pattern: "*.go", file: "internal/service/pr.go", want: true},
+		{pattern: "*.go", file: "README.md", want: false},
+		{pattern: "/docs/", file: "docs/api/openapi.yaml", want: true},
+		{pattern: "/docs/", file: "internal/docs/readme.md", want: false},
+		{pattern: "docs/", file: "internal/docs/readme.md", want: true},
+		{pattern: "services/payments", file: "services/payments/api/handler.go", want: true},
+		{pattern: "services/payments", file: "other/services/payments/handler.go", want: false},
+		{pattern: "services/*/migrations", file: "services/search/migrations/001.sql", want: true},
+		{pattern: "services/**/*.sql", file: "services/search/db/migrations/001.sql", want: true},
+		{pattern: "services/**/*.sql", file: "services/search/db/query.go", want: false},
+		{pattern: "/apps", file: "./apps/web/index.ts", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.file, func(t *testing.T) {
+			if got := matchOwnerPattern(tt.pattern, tt.file); got != tt.want {
+				t.Fatalf("want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMatchingOwnerRules_LastMatchWins(t *testing.T) {
+	rules := []domain.CodeOwnerRule{
+		{Pattern: "*", Teams: []string{"platform"}},
+		{Pattern: "/services/payments/", Teams: []string{"payments"}},
+		{Pattern: "*.md", Users: []string{"docs-writer"}},
+	}
+
+	got := matchingOwnerRules(rules, []string{
+		"services/payments/api.go",
+		"services/payments/README.md",
+		"services/payments/db.go",
+	})
+	if len(got) != 2 || got[0].Pattern != "/services/payments/" || got[1].Pattern != "*.md" {
+		t.Fatalf("unexpected matched rules: %+v", got)
+	}
+
+	if got = matchingOwnerRules(rules, nil); len(got) != 0 {
+		t.Fatalf("expected no rules without files, got %+v", got)
+	}
+}
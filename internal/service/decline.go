@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/neizhmak/avito-review-service/internal/domain"
+	"github.com/neizhmak/avito-review-service/internal/storage"
+)
+
+// Decline lets an assigned reviewer step down from a pull request with a reason. The decline is recorded and a
+// replacement is picked as Reassign does; the decliner is not picked for the pull request again. If no replacement
+// is found the reviewer is still removed and the pull request is marked as needing reviewers. It returns the id of
+// the new reviewer, or an empty string if none was found. A shadow reviewer is simply removed.
+func (s *PRService) Decline(ctx context.Context, prID, reviewerID string, reason domain.DeclineReason) (string, error) {
+	pr, reviewer, err := s.assignedReviewer(ctx, prID, reviewerID, "decline review on")
+	if err != nil {
+		return "", err
+	}
+
+	shadow := pr.IsShadowReviewer(reviewerID)
+	var picked []assignment
+	if !shadow {
+		picked, err = s.pickReplacement(ctx, *pr, *reviewer, nil)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	decline := domain.ReviewDecline{PRID: prID, UserID: reviewerID, Reason: reason}
+	if err = s.prStorage.SaveDecline(ctx, tx, decline); err != nil {
+		return "", err
+	}
+	if err = s.prStorage.DeleteReviewer(ctx, tx, prID, reviewerID); err != nil {
+		return "", err
+	}
+	err = s.recordEvent(ctx, tx, domain.PREvent{PRID: prID, Type: domain.PREventDeclined, ReviewerID: reviewerID, Reason: string(reason), Shadow: shadow})
+	if err != nil {
+		return "", err
+	}
+	if err = s.saveAssignments(ctx, tx, *pr, picked, "declined by "+reviewerID); err != nil {
+		return "", err
+	}
+	if len(picked) == 0 && !shadow {
+		if err = s.prStorage.SetNeedsReviewers(ctx, tx, prID, true); err != nil {
+			return "", err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to commit tx: %w", err)
+	}
+
+	if len(picked) == 0 {
+		return "", nil
+	}
+	return picked[0].User.ID, nil
+}
+
+// GetDeclines lists the declines recorded for a pull request.
+func (s *PRService) GetDeclines(ctx context.Context, prID string) ([]domain.ReviewDecline, error) {
+	if _, err := s.prStorage.GetByID(ctx, prID); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, notFound("pr not found")
+		}
+		return nil, fmt.Errorf("failed to get pr: %w", err)
+	}
+	return s.prStorage.GetDeclines(ctx, prID)
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/neizhmak/avito-review-service/internal/domain"
 	"github.com/neizhmak/avito-review-service/internal/storage"
@@ -11,13 +12,34 @@ import (
 type PullRequestRepository interface {
 	Save(ctx context.Context, executor storage.QueryExecutor, pr domain.PullRequest) error
 	GetByID(ctx context.Context, id string) (*domain.PullRequest, error)
-	UpdateStatus(ctx context.Context, executor storage.QueryExecutor, id string, status domain.PRStatus) error
+	UpdateStatus(ctx context.Context, executor storage.QueryExecutor, id string, from []domain.PRStatus, status domain.PRStatus) error
+	MarkForceMerged(ctx context.Context, executor storage.QueryExecutor, id string) error
+	SetNeedsReviewers(ctx context.Context, executor storage.QueryExecutor, id string, needs bool) error
+	LockNeedingReviewers(ctx context.Context, executor storage.QueryExecutor, id string) (bool, error)
+	LockStatus(ctx context.Context, executor storage.QueryExecutor, id string) (domain.PRStatus, error)
+	GetIDsNeedingReviewers(ctx context.Context) ([]string, error)
 	GetReviewers(ctx context.Context, prID string) ([]string, error)
+	GetReviewerAssignments(ctx context.Context, prID string) ([]domain.AssignedReviewer, error)
+	UpdateReviewState(ctx context.Context, executor storage.QueryExecutor, prID, reviewerID string, state domain.ReviewState) error
 	DeleteReviewer(ctx context.Context, executor storage.QueryExecutor, prID string, userID string) error
 	SaveReviewer(ctx context.Context, executor storage.QueryExecutor, prID, reviewerID string) error
+	SaveFallbackReviewer(ctx context.Context, executor storage.QueryExecutor, prID, reviewerID, fallbackTeam string) error
+	SaveShadowReviewer(ctx context.Context, executor storage.QueryExecutor, prID, reviewerID string) error
 	GetByReviewerID(ctx context.Context, reviewerID string) ([]domain.PullRequest, error)
+	GetOpenIDsByReviewerTeam(ctx context.Context, teamName string) ([]string, error)
 	RemoveReviewersByTeam(ctx context.Context, executor storage.QueryExecutor, teamName string) error
+	GetOpenReviewCountsByTeam(ctx context.Context, teamName string) (map[string]int, error)
+	SetReviewDueAt(ctx context.Context, executor storage.QueryExecutor, prID, reviewerID string, due time.Time) error
+	MarkEscalated(ctx context.Context, executor storage.QueryExecutor, prID, reviewerID string) error
+	GetOverdueReviews(ctx context.Context, teamName string, at time.Time) ([]domain.OverdueReview, error)
+	GetOpenReviewSizesByTeam(ctx context.Context, teamName string) (map[string]map[domain.PRSize]int, error)
+	GetRecentPairings(ctx context.Context, authorID string, since time.Time) (map[string]int, error)
+	GetPairingMatrix(ctx context.Context, teamName string, since time.Time) (map[string]map[string]int, error)
 	GetSystemStats(ctx context.Context) (*domain.SystemStats, error)
+	SaveDecline(ctx context.Context, executor storage.QueryExecutor, decline domain.ReviewDecline) error
+	GetDeclines(ctx context.Context, prID string) ([]domain.ReviewDecline, error)
+	SaveEvent(ctx context.Context, executor storage.QueryExecutor, event domain.PREvent) (*domain.PREvent, error)
+	GetEvents(ctx context.Context, prID string) ([]domain.PREvent, error)
 }
 
 // UserRepository defines persistence operations for users.
@@ -25,13 +47,46 @@ type UserRepository interface {
 	GetByID(ctx context.Context, id string) (*domain.User, error)
 	GetActiveUsersByTeam(ctx context.Context, teamName string) ([]domain.User, error)
 	Save(ctx context.Context, user domain.User) error
-	UpdateActivity(ctx context.Context, userID string, isActive bool) error
+	UpdateActivity(ctx context.Context, executor storage.QueryExecutor, userID string, isActive bool) error
 	GetUsersByTeam(ctx context.Context, teamName string) ([]domain.User, error)
+	SetMaxOpenReviews(ctx context.Context, userID string, limit *int) error
+	SetRole(ctx context.Context, userID string, role domain.UserRole) error
+	UpdateProfile(ctx context.Context, user domain.User) error
 	MassDeactivate(ctx context.Context, executor storage.QueryExecutor, teamName string) error
+	IsUnavailable(ctx context.Context, userID string, at time.Time) (bool, error)
+	AddExclusionRule(ctx context.Context, rule domain.ExclusionRule) (int64, error)
+	GetExclusionRules(ctx context.Context, userID string) ([]domain.ExclusionRule, error)
+	DeleteExclusionRule(ctx context.Context, id int64) error
+	GetBlockedReviewers(ctx context.Context, authorID string, at time.Time) ([]string, error)
+	AddUnavailability(ctx context.Context, period domain.Unavailability) (int64, error)
+	GetUnavailability(ctx context.Context, userID string) ([]domain.Unavailability, error)
+	DeleteUnavailability(ctx context.Context, userID string, id int64) error
 }
 
 // TeamRepository defines persistence operations for teams.
 type TeamRepository interface {
 	GetByName(ctx context.Context, name string) (*domain.Team, error)
 	Save(ctx context.Context, team domain.Team) error
+	GetSettings(ctx context.Context, teamName string) (*domain.TeamSettings, error)
+	SaveSettings(ctx context.Context, executor storage.QueryExecutor, settings domain.TeamSettings) error
+	GetRoundRobinCursor(ctx context.Context, teamName string) (string, error)
+	SetRoundRobinCursor(ctx context.Context, teamName, userID string) error
+	GetCodeOwnerRules(ctx context.Context) ([]domain.CodeOwnerRule, error)
+	ReplaceCodeOwnerRules(ctx context.Context, executor storage.QueryExecutor, rules []domain.CodeOwnerRule) error
+	SaveHoliday(ctx context.Context, holiday domain.Holiday) error
+	GetHolidays(ctx context.Context, teamName string) ([]domain.Holiday, error)
+	DeleteHoliday(ctx context.Context, teamName, date string) error
+}
+
+// WebhookRepository defines persistence operations for webhook subscriptions and their deliveries.
+type WebhookRepository interface {
+	Create(ctx context.Context, webhook domain.Webhook) (*domain.Webhook, error)
+	GetByID(ctx context.Context, id int64) (*domain.Webhook, error)
+	List(ctx context.Context) ([]domain.Webhook, error)
+	Delete(ctx context.Context, id int64) error
+	EnqueueDeliveries(ctx context.Context, executor storage.QueryExecutor, eventType domain.WebhookEventType, payload []byte) error
+	ClaimDueDeliveries(ctx context.Context, at time.Time, limit int, lease time.Duration) ([]domain.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery domain.WebhookDelivery) error
+	GetDeliveries(ctx context.Context, webhookID int64, limit int) ([]domain.WebhookDelivery, error)
+	Redeliver(ctx context.Context, deliveryID int64) (*domain.WebhookDelivery, error)
 }
@@ -7,6 +7,17 @@ const (
 	ErrCodeNotAssigned = "NOT_ASSIGNED"
 	ErrCodeNoCandidate = "NO_CANDIDATE"
 	ErrCodeNotFound    = "NOT_FOUND"
+
+	ErrCodeUnknownStrategy  = "UNKNOWN_STRATEGY"
+	ErrCodeInvalidSettings  = "INVALID_SETTINGS"
+	ErrCodeInvalidRule      = "INVALID_RULE"
+	ErrCodeMergeBlocked     = "MERGE_BLOCKED"
+	ErrCodeInvalidStatus    = "INVALID_STATUS"
+	ErrCodeInvalidPeriod    = "INVALID_PERIOD"
+	ErrCodeCapacityExceeded = "CAPACITY_EXCEEDED"
+	ErrCodeInvalidReviewer  = "INVALID_REVIEWER"
+	ErrCodeAlreadyAssigned  = "ALREADY_ASSIGNED"
+	ErrCodeInvalidWebhook   = "INVALID_WEBHOOK"
 )
 
 type ServiceError struct {
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/neizhmak/avito-review-service/internal/domain"
+	"github.com/neizhmak/avito-review-service/internal/storage"
+)
+
+// AddExclusionRule stores a rule forbidding a user from reviewing another user's pull requests.
+// Reviews already assigned are left in place; the rule applies to all later picks, including manual ones.
+func (s *PRService) AddExclusionRule(ctx context.Context, rule domain.ExclusionRule) (*domain.ExclusionRule, error) {
+	if rule.ReviewerID == rule.AuthorID {
+		return nil, newServiceError(ErrCodeInvalidRule, "reviewer_id and author_id must differ")
+	}
+	if rule.Until != nil && !rule.Until.After(time.Now()) {
+		return nil, newServiceError(ErrCodeInvalidRule, "until must be in the future")
+	}
+	for _, id := range []string{rule.ReviewerID, rule.AuthorID} {
+		if _, err := s.userStorage.GetByID(ctx, id); err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				return nil, notFound("user " + id + " not found")
+			}
+			return nil, err
+		}
+	}
+	rule.Reason = strings.TrimSpace(rule.Reason)
+
+	id, err := s.userStorage.AddExclusionRule(ctx, rule)
+	if err != nil {
+		return nil, err
+	}
+	rule.ID = id
+
+	return &rule, nil
+}
+
+// GetExclusionRules lists the exclusion rules in force, optionally only those naming the given user.
+func (s *PRService) GetExclusionRules(ctx context.Context, userID string) ([]domain.ExclusionRule, error) {
+	return s.userStorage.GetExclusionRules(ctx, userID)
+}
+
+// DeleteExclusionRule removes an exclusion rule.
+func (s *PRService) DeleteExclusionRule(ctx context.Context, id int64) error {
+	if err := s.userStorage.DeleteExclusionRule(ctx, id); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return notFound("exclusion rule not found")
+		}
+		return err
+	}
+	return nil
+}
+
+// blockedReviewers returns the set of users that exclusion rules currently forbid from reviewing the author's
+// pull requests.
+func (s *PRService) blockedReviewers(ctx context.Context, authorID string) (map[string]bool, error) {
+	ids, err := s.userStorage.GetBlockedReviewers(ctx, authorID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	blocked := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		blocked[id] = true
+	}
+	return blocked, nil
+}
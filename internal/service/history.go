@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/neizhmak/avito-review-service/internal/domain"
+	"github.com/neizhmak/avito-review-service/internal/storage"
+)
+
+// Strategies recorded for reviewers that were not chosen by a reviewer selector.
+const (
+	strategyRequested = "requested"
+	strategyManual    = "manual"
+)
+
+type actorKey struct{}
+
+// WithActor returns a context in which changes to pull requests are recorded in their history as made by actor.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// actorFrom returns the actor set by WithActor, or SystemActor if there is none.
+func actorFrom(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return domain.SystemActor
+}
+
+// webhookEvents maps history events to the webhook events they are published as.
+var webhookEvents = map[domain.PREventType]domain.WebhookEventType{
+	domain.PREventAssigned:   domain.WebhookReviewerAssigned,
+	domain.PREventReassigned: domain.WebhookReviewerReassigned,
+	domain.PREventMerged:     domain.WebhookPRMerged,
+	domain.PREventEscalated:  domain.WebhookReviewOverdue,
+}
+
+// recordEvent appends an event made by the actor of ctx to the history of a pull request and publishes it to
+// webhook subscribers.
+func (s *PRService) recordEvent(ctx context.Context, executor storage.QueryExecutor, event domain.PREvent) error {
+	event.Actor = actorFrom(ctx)
+	saved, err := s.prStorage.SaveEvent(ctx, executor, event)
+	if err != nil {
+		return err
+	}
+	if eventType, ok := webhookEvents[saved.Type]; ok {
+		return s.publish(ctx, executor, eventType, saved)
+	}
+	return nil
+}
+
+// recordRemoval records that a reviewer left a pull request without a replacement.
+func (s *PRService) recordRemoval(ctx context.Context, executor storage.QueryExecutor, prID, reviewerID, reason string, shadow bool) error {
+	event := domain.PREvent{PRID: prID, Type: domain.PREventRemoved, ReviewerID: reviewerID, Reason: reason, Shadow: shadow}
+	return s.recordEvent(ctx, executor, event)
+}
+
+// GetPRHistory lists the recorded assignment events of a pull request, oldest first.
+func (s *PRService) GetPRHistory(ctx context.Context, prID string) ([]domain.PREvent, error) {
+	if _, err := s.prStorage.GetByID(ctx, prID); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, notFound("pr not found")
+		}
+		return nil, fmt.Errorf("failed to get pr: %w", err)
+	}
+	return s.prStorage.GetEvents(ctx, prID)
+}
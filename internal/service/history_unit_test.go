@@ -0,0 +1,21 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/neizhmak/avito-review-service/internal/domain"
+)
+
+func TestActorFrom(t *testing.T) {
+	ctx := context.Background()
+	if got := actorFrom(ctx); got != domain.SystemActor {
+		t.Fatalf("want %s without an actor, got %s", domain.SystemActor, got)
+	}
+	if got := actorFrom(WithActor(ctx, "")); got != domain.SystemActor {
+		t.Fatalf("want %s for an empty actor, got %s", domain.SystemActor, got)
+	}
+	if got := actorFrom(WithActor(ctx, "alice")); got != "alice" {
+		t.Fatalf("want alice, got %s", got)
+	}
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/neizhmak/avito-review-service/internal/domain"
+	"github.com/neizhmak/avito-review-service/internal/storage"
+)
+
+// Ready moves a draft pull request to OPEN and assigns its reviewers. The operation is idempotent.
+func (s *PRService) Ready(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	pr, err := s.GetPR(ctx, prID)
+	if err != nil {
+		return nil, err
+	}
+	if pr.Status == domain.PRStatusOpen {
+		return pr, nil
+	}
+	if pr.Status != domain.PRStatusDraft {
+		return nil, conflict(ErrCodeInvalidStatus, "only draft PR can be marked ready")
+	}
+
+	return s.openPR(ctx, pr)
+}
+
+// Close abandons a draft or open pull request. Its reviews stop counting towards reviewer load.
+// The operation is idempotent.
+func (s *PRService) Close(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	pr, err := s.GetPR(ctx, prID)
+	if err != nil {
+		return nil, err
+	}
+	switch pr.Status {
+	case domain.PRStatusClosed:
+		return pr, nil
+	case domain.PRStatusMerged:
+		return nil, conflict(ErrCodePRMerged, "cannot close merged PR")
+	}
+
+	from := []domain.PRStatus{domain.PRStatusDraft, domain.PRStatusOpen}
+	if err = s.prStorage.UpdateStatus(ctx, s.db, prID, from, domain.PRStatusClosed); err != nil {
+		return nil, statusChanged(err)
+	}
+
+	return s.GetPR(ctx, prID)
+}
+
+// Reopen moves a closed pull request back to OPEN, keeping its reviewers and their review states.
+// A pull request closed as a draft gets its reviewers assigned now. The operation is idempotent.
+func (s *PRService) Reopen(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	pr, err := s.GetPR(ctx, prID)
+	if err != nil {
+		return nil, err
+	}
+	if pr.Status == domain.PRStatusOpen {
+		return pr, nil
+	}
+	if pr.Status != domain.PRStatusClosed {
+		return nil, conflict(ErrCodeInvalidStatus, "only closed PR can be reopened")
+	}
+
+	return s.openPR(ctx, pr)
+}
+
+// openPR sets the pull request status to OPEN, assigning reviewers if it has none.
+func (s *PRService) openPR(ctx context.Context, pr *domain.PullRequest) (*domain.PullRequest, error) {
+	author, err := s.userStorage.GetByID(ctx, pr.AuthorID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, notFound("author not found")
+		}
+		return nil, fmt.Errorf("failed to get author: %w", err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err = s.prStorage.UpdateStatus(ctx, tx, pr.ID, []domain.PRStatus{pr.Status}, domain.PRStatusOpen); err != nil {
+		return nil, statusChanged(err)
+	}
+	if len(pr.Reviewers) == 0 {
+		if err = s.assignInitialReviewers(ctx, tx, pr, author.TeamName); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit tx: %w", err)
+	}
+
+	return s.GetPR(ctx, pr.ID)
+}
+
+// statusChanged turns a status update that found the pull request in another status, because a concurrent request
+// changed it after it was read, into an INVALID_STATUS conflict.
+func statusChanged(err error) error {
+	if errors.Is(err, storage.ErrNotFound) {
+		return conflict(ErrCodeInvalidStatus, "pr status was changed concurrently")
+	}
+	return err
+}
+
+// requireOpen returns a conflict error unless the pull request is OPEN.
+func requireOpen(pr *domain.PullRequest, action string) error {
+	switch pr.Status {
+	case domain.PRStatusOpen:
+		return nil
+	case domain.PRStatusMerged:
+		return conflict(ErrCodePRMerged, "cannot "+action+" merged PR")
+	default:
+		return conflict(ErrCodeInvalidStatus, "cannot "+action+" "+strings.ToLower(string(pr.Status))+" PR")
+	}
+}
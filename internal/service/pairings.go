@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/neizhmak/avito-review-service/internal/domain"
+)
+
+// defaultPairingReportDays is the report period used when neither the request nor the team sets a pairing window.
+const defaultPairingReportDays = 30
+
+// GetPairingMatrix reports how often each reviewer was assigned to each author of the team over the last days.
+// If days is not positive, the team's pairing window is used, or 30 days when the window is disabled.
+func (s *PRService) GetPairingMatrix(ctx context.Context, teamName string, days int) (*domain.PairingMatrix, error) {
+	settings, err := s.GetTeamSettings(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	if days <= 0 {
+		days = settings.PairingWindowDays
+	}
+	if days <= 0 {
+		days = defaultPairingReportDays
+	}
+	since := time.Now().AddDate(0, 0, -days)
+
+	pairs, err := s.prStorage.GetPairingMatrix(ctx, teamName, since)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.PairingMatrix{TeamName: teamName, Since: since, Pairs: pairs}, nil
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/neizhmak/avito-review-service/internal/domain"
+)
+
+// GetUnassigned lists OPEN pull requests waiting for more reviewers, oldest first.
+func (s *PRService) GetUnassigned(ctx context.Context) ([]domain.PullRequest, error) {
+	ids, err := s.prStorage.GetIDsNeedingReviewers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	prs := make([]domain.PullRequest, 0, len(ids))
+	for _, id := range ids {
+		pr, err := s.prStorage.GetByID(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pr: %w", err)
+		}
+		prs = append(prs, *pr)
+	}
+	return prs, nil
+}
+
+// FillPendingReviewers tries to add the missing reviewers to every pull request waiting for them, picking as Create
+// does; a senior that was not available when the reviewers were picked is added on top. Pull requests that reach
+// their team's reviewer_count, with a senior if the team requires one, are no longer marked. A pull request that
+// cannot be filled is logged and skipped, so it does not hold up the others. It returns how many pull requests got
+// at least one new reviewer.
+func (s *PRService) FillPendingReviewers(ctx context.Context) (int, error) {
+	ids, err := s.prStorage.GetIDsNeedingReviewers(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	filled := 0
+	for _, id := range ids {
+		added, err := s.fillPendingPR(ctx, id)
+		if err != nil {
+			slog.Error("failed to fill pending reviewers", "pull_request_id", id, "error", err)
+			continue
+		}
+		if added > 0 {
+			filled++
+		}
+	}
+	return filled, nil
+}
+
+// fillPendingPR adds missing reviewers to a single pull request and returns how many were added. The pull request
+// row stays locked while reviewers are picked, and nothing is done if it no longer waits for reviewers.
+func (s *PRService) fillPendingPR(ctx context.Context, prID string) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	needs, err := s.prStorage.LockNeedingReviewers(ctx, tx, prID)
+	if err != nil || !needs {
+		return 0, err
+	}
+
+	pr, err := s.prStorage.GetByID(ctx, prID)
+	if err != nil {
+		return 0, err
+	}
+	author, err := s.userStorage.GetByID(ctx, pr.AuthorID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get author: %w", err)
+	}
+	settings, err := s.teamStorage.GetSettings(ctx, author.TeamName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get team settings: %w", err)
+	}
+
+	assigned, err := s.requiredReviewers(ctx, *pr)
+	if err != nil {
+		return 0, err
+	}
+
+	var picked []assignment
+	required := pr.RequiredReviewerCount()
+	reviewerCount := settings.ReviewerCountFor(pr.Size)
+	exclude := append([]string{pr.AuthorID}, pr.ReviewerIDs()...)
+	if missing := reviewerCount - required; missing > 0 {
+		picked, err = s.pickForPR(ctx, *pr, *settings, author.TeamName, assigned, exclude, missing)
+	} else if lacksSenior(*settings, assigned) {
+		// the reviewer slots were filled while no senior was available; the senior is added on top
+		picked, err = s.pickMissingSenior(ctx, *pr, *settings, author.TeamName, exclude)
+	}
+	if err != nil {
+		return 0, err
+	}
+	done := required+len(picked) >= reviewerCount && !lacksSenior(*settings, append(assigned, assignedUsers(picked)...))
+	if len(picked) == 0 && !done {
+		return 0, nil
+	}
+
+	if err = s.saveAssignments(ctx, tx, *pr, picked, "pending reviewers filled"); err != nil {
+		return 0, err
+	}
+	if done {
+		if err = s.prStorage.SetNeedsReviewers(ctx, tx, pr.ID, false); err != nil {
+			return 0, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit tx: %w", err)
+	}
+	return len(picked), nil
+}
+
+// pickMissingSenior picks the senior the author team requires for a pull request whose reviewer slots are taken.
+func (s *PRService) pickMissingSenior(
+	ctx context.Context,
+	pr domain.PullRequest,
+	settings domain.TeamSettings,
+	homeTeam string,
+	exclude []string,
+) ([]assignment, error) {
+	barred, err := s.barredReviewers(ctx, pr)
+	if err != nil {
+		return nil, err
+	}
+	picked, err := s.pickSenior(ctx, settings, homeTeam, pr.AuthorID, append(append([]string(nil), exclude...), barred...))
+	if err != nil {
+		return nil, err
+	}
+	for i := range picked {
+		picked[i].Reason = "senior required"
+	}
+	return picked, nil
+}
+
+// RunPendingReviewerWorker calls FillPendingReviewers every interval until ctx is cancelled. This is how pull requests
+// get their reviewers once users are reactivated, join the team or drop below their review capacity.
+func (s *PRService) RunPendingReviewerWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			filled, err := s.FillPendingReviewers(ctx)
+			if err != nil {
+				slog.Error("failed to fill pending reviewers", "error", err)
+				continue
+			}
+			if filled > 0 {
+				slog.Info("filled pending reviewers", "pull_requests", filled)
+			}
+		}
+	}
+}
@@ -5,7 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"math/rand"
+	"net/http"
 	"time"
 
 	"github.com/neizhmak/avito-review-service/internal/domain"
@@ -13,10 +13,15 @@ import (
 )
 
 type PRService struct {
-	prStorage   PullRequestRepository
-	userStorage UserRepository
-	teamStorage TeamRepository
-	db          *sql.DB
+	prStorage      PullRequestRepository
+	userStorage    UserRepository
+	teamStorage    TeamRepository
+	webhookStorage WebhookRepository
+	selectors      map[domain.ReviewerStrategy]ReviewerSelector
+	httpClient     *http.Client
+	// allowPrivateWebhooks lets webhooks target loopback and private network addresses.
+	allowPrivateWebhooks bool
+	db                   *sql.DB
 }
 
 func NewPRService(
@@ -29,13 +34,43 @@ func NewPRService(
 		prStorage:   prStorage,
 		userStorage: userStorage,
 		teamStorage: teamStorage,
+		selectors:   defaultSelectors(),
+		httpClient:  newWebhookClient(false),
 		db:          db,
 	}
 }
 
+// RegisterSelector makes a custom reviewer selection strategy available to teams under the given name.
+// It is not safe to call concurrently with request handling and is meant to be used during setup.
+func (s *PRService) RegisterSelector(strategy domain.ReviewerStrategy, selector ReviewerSelector) {
+	s.selectors[strategy] = selector
+}
+
+// SetWebhookStorage enables outbound webhooks. Without it events are not published and the webhook methods fail.
+// Like RegisterSelector it is meant to be called during setup.
+func (s *PRService) SetWebhookStorage(webhookStorage WebhookRepository) {
+	s.webhookStorage = webhookStorage
+}
+
+// AllowPrivateWebhooks lets webhooks target loopback, link-local and private network addresses, which are refused
+// by default so that subscribers cannot make the service call internal endpoints. It is meant to be called during
+// setup.
+func (s *PRService) AllowPrivateWebhooks() {
+	s.allowPrivateWebhooks = true
+	s.httpClient = newWebhookClient(true)
+}
+
 // Create creates a new pull request and assigns reviewers.
+// A pull request created as DRAFT gets no reviewers until it is marked ready.
 func (s *PRService) Create(ctx context.Context, pr domain.PullRequest) (*domain.PullRequest, error) {
-	pr.Status = domain.PRStatusOpen
+	if pr.Status != domain.PRStatusDraft {
+		pr.Status = domain.PRStatusOpen
+	}
+	pr.Labels = domain.NormalizeTags(pr.Labels)
+	pr.Size = ""
+	if pr.DiffStats != nil {
+		pr.Size = pr.DiffStats.Size()
+	}
 
 	// Validate author
 	author, err := s.userStorage.GetByID(ctx, pr.AuthorID)
@@ -66,16 +101,14 @@ func (s *PRService) Create(ctx context.Context, pr domain.PullRequest) (*domain.
 		return nil, fmt.Errorf("failed to save pr: %w", err)
 	}
 
-	candidates, err := s.userStorage.GetActiveUsersByTeam(ctx, author.TeamName)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get candidates: %w", err)
+	// reviewers are published as reviewer.assigned events right after
+	pr.Reviewers = []domain.AssignedReviewer{}
+	if err = s.publish(ctx, tx, domain.WebhookPRCreated, pr); err != nil {
+		return nil, err
 	}
-
-	reviewers := selectRandomReviewers(candidates, pr.AuthorID, 2)
-
-	for _, r := range reviewers {
-		if err = s.prStorage.SaveReviewer(ctx, tx, pr.ID, r.ID); err != nil {
-			return nil, fmt.Errorf("failed to save reviewer: %w", err)
+	if pr.Status == domain.PRStatusOpen {
+		if err = s.assignInitialReviewers(ctx, tx, &pr, author.TeamName); err != nil {
+			return nil, err
 		}
 	}
 
@@ -83,32 +116,12 @@ func (s *PRService) Create(ctx context.Context, pr domain.PullRequest) (*domain.
 		return nil, fmt.Errorf("failed to commit tx: %w", err)
 	}
 
-	for _, r := range reviewers {
-		pr.Reviewers = append(pr.Reviewers, r.ID)
-	}
-
 	return &pr, nil
 }
 
-// selectRandomReviewers selects a specified number of random reviewers from the candidates, excluding the author.
-func selectRandomReviewers(candidates []domain.User, authorID string, count int) []domain.User {
-	var valid []domain.User
-	for _, u := range candidates {
-		if u.ID != authorID {
-			valid = append(valid, u)
-		}
-	}
-
-	rand.Shuffle(len(valid), func(i, j int) { valid[i], valid[j] = valid[j], valid[i] })
-
-	if len(valid) < count {
-		return valid
-	}
-	return valid[:count]
-}
-
-// Merge marks a pull request as merged. The operation is idempotent.
-func (s *PRService) Merge(ctx context.Context, prID string) (*domain.PullRequest, error) {
+// Merge marks a pull request as merged if it satisfies the merge policy of the author's team.
+// With force the policy is bypassed and the pull request is recorded as force merged. The operation is idempotent.
+func (s *PRService) Merge(ctx context.Context, prID string, force bool) (*domain.PullRequest, error) {
 	pr, err := s.prStorage.GetByID(ctx, prID)
 	if err != nil {
 		if errors.Is(err, storage.ErrNotFound) {
@@ -120,87 +133,108 @@ func (s *PRService) Merge(ctx context.Context, prID string) (*domain.PullRequest
 	if pr.Status == domain.PRStatusMerged {
 		return pr, nil
 	}
-
-	if err := s.prStorage.UpdateStatus(ctx, s.db, prID, domain.PRStatusMerged); err != nil {
+	if err = requireOpen(pr, "merge"); err != nil {
 		return nil, err
 	}
 
-	pr.Status = domain.PRStatusMerged
-	now := time.Now()
-	pr.MergedAt = &now
-
-	return pr, nil
-}
+	author, err := s.userStorage.GetByID(ctx, pr.AuthorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get author: %w", err)
+	}
+	settings, err := s.teamStorage.GetSettings(ctx, author.TeamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team settings: %w", err)
+	}
 
-// Reassign replaces an existing reviewer on a pull request with a new one from the same team.
-func (s *PRService) Reassign(ctx context.Context, prID, oldUserID string) (string, error) {
-	pr, err := s.prStorage.GetByID(ctx, prID)
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		if errors.Is(err, storage.ErrNotFound) {
-			return "", notFound("pr not found")
-		}
-		return "", fmt.Errorf("pr not found: %w", err)
+		return nil, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// review submissions and concurrent merges wait for the lock, so the policy sees the final reviews
+	if pr.Status, err = s.prStorage.LockStatus(ctx, tx, prID); err != nil {
+		return nil, err
 	}
 	if pr.Status == domain.PRStatusMerged {
-		return "", conflict(ErrCodePRMerged, "cannot reassign on merged PR")
+		return s.GetPR(ctx, prID)
+	}
+	if err = requireOpen(pr, "merge"); err != nil {
+		return nil, err
+	}
+	if pr.Reviewers, err = s.prStorage.GetReviewerAssignments(ctx, prID); err != nil {
+		return nil, fmt.Errorf("failed to get reviewers: %w", err)
+	}
+	if !force {
+		if err = checkMergePolicy(*settings, pr.Reviewers); err != nil {
+			return nil, err
+		}
 	}
 
-	currentReviewers, err := s.prStorage.GetReviewers(ctx, prID)
-	if err != nil {
-		return "", err
+	if err = s.prStorage.UpdateStatus(ctx, tx, prID, []domain.PRStatus{domain.PRStatusOpen}, domain.PRStatusMerged); err != nil {
+		return nil, statusChanged(err)
 	}
-	isAssigned := false
-	for _, id := range currentReviewers {
-		if id == oldUserID {
-			isAssigned = true
-			break
+	event := domain.PREvent{PRID: prID, Type: domain.PREventMerged}
+	if force {
+		if err = s.prStorage.MarkForceMerged(ctx, tx, prID); err != nil {
+			return nil, err
 		}
+		event.Reason = "merge policy bypassed with force"
 	}
-	if !isAssigned {
-		return "", conflict(ErrCodeNotAssigned, "reviewer is not assigned to this PR")
+	if err = s.recordEvent(ctx, tx, event); err != nil {
+		return nil, err
 	}
 
-	oldUser, err := s.userStorage.GetByID(ctx, oldUserID)
-	if err != nil {
-		if errors.Is(err, storage.ErrNotFound) {
-			return "", notFound("user not found")
-		}
-		return "", fmt.Errorf("failed to get old reviewer info: %w", err)
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit tx: %w", err)
 	}
 
-	candidates, err := s.userStorage.GetActiveUsersByTeam(ctx, oldUser.TeamName)
+	pr.Status = domain.PRStatusMerged
+	pr.ForceMerged = force
+	now := time.Now()
+	pr.MergedAt = &now
+
+	return pr, nil
+}
+
+// Reassign replaces an existing reviewer on a pull request. If newUserID is set, that user takes over the review
+// after the same checks as AddReviewer. Otherwise a replacement is picked from the old reviewer's team; if that team
+// has no candidates left, the author team and its fallback teams are tried in order. Shadow reviewers are not
+// reassigned.
+func (s *PRService) Reassign(ctx context.Context, prID, oldUserID, newUserID string) (string, error) {
+	pr, oldUser, err := s.assignedReviewer(ctx, prID, oldUserID, "reassign on")
 	if err != nil {
 		return "", err
 	}
+	if pr.IsShadowReviewer(oldUserID) {
+		return "", conflict(ErrCodeInvalidReviewer, "shadow reviewers are not reassigned; remove them instead")
+	}
 
-	var validCandidates []domain.User
-	for _, cand := range candidates {
-		if cand.ID == pr.AuthorID {
-			continue
+	var picked []assignment
+	if newUserID != "" {
+		author, err := s.userStorage.GetByID(ctx, pr.AuthorID)
+		if err != nil {
+			return "", fmt.Errorf("failed to get author: %w", err)
 		}
-		if cand.ID == oldUserID {
-			continue
+		target, err := s.manualAssignment(ctx, *pr, *author, newUserID)
+		if err != nil {
+			return "", err
 		}
-
-		alreadyReviewing := false
-		for _, rID := range currentReviewers {
-			if rID == cand.ID {
-				alreadyReviewing = true
-				break
-			}
+		if err = s.checkSeniorKept(ctx, *pr, *author, *oldUser, target.User); err != nil {
+			return "", err
 		}
-		if alreadyReviewing {
-			continue
+		target.Replaces = oldUserID
+		picked = []assignment{target}
+	} else {
+		picked, err = s.pickReplacement(ctx, *pr, *oldUser, nil)
+		if err != nil {
+			return "", err
+		}
+		if len(picked) == 0 {
+			return "", s.noReplacementError(ctx, *pr, *oldUser)
 		}
-
-		validCandidates = append(validCandidates, cand)
-	}
-
-	if len(validCandidates) == 0 {
-		return "", conflict(ErrCodeNoCandidate, "no active replacement candidate in team")
 	}
-
-	newReviewer := selectRandomReviewers(validCandidates, "", 1)[0]
+	newReviewer := picked[0].User
 
 	// transactional update
 	tx, err := s.db.BeginTx(ctx, nil)
@@ -214,7 +248,7 @@ func (s *PRService) Reassign(ctx context.Context, prID, oldUserID string) (strin
 	if err = s.prStorage.DeleteReviewer(ctx, tx, prID, oldUserID); err != nil {
 		return "", err
 	}
-	if err = s.prStorage.SaveReviewer(ctx, tx, prID, newReviewer.ID); err != nil {
+	if err = s.saveAssignments(ctx, tx, *pr, picked, "reassign requested"); err != nil {
 		return "", err
 	}
 
@@ -225,6 +259,84 @@ func (s *PRService) Reassign(ctx context.Context, prID, oldUserID string) (strin
 	return newReviewer.ID, nil
 }
 
+// checkSeniorKept returns an INVALID_REVIEWER error if the team requires a senior reviewer and replacing oldUser
+// with newUser would leave the pull request without one.
+func (s *PRService) checkSeniorKept(ctx context.Context, pr domain.PullRequest, author, oldUser, newUser domain.User) error {
+	if !oldUser.Role.IsSenior() || newUser.Role.IsSenior() {
+		return nil
+	}
+	settings, err := s.teamStorage.GetSettings(ctx, author.TeamName)
+	if err != nil {
+		return fmt.Errorf("failed to get team settings: %w", err)
+	}
+	if !settings.RequireSenior {
+		return nil
+	}
+
+	for _, r := range pr.Reviewers {
+		if r.Shadow || r.UserID == oldUser.ID {
+			continue
+		}
+		u, err := s.userStorage.GetByID(ctx, r.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to get reviewer: %w", err)
+		}
+		if u.Role.IsSenior() {
+			return nil
+		}
+	}
+	return conflict(ErrCodeInvalidReviewer, "team "+author.TeamName+" requires a senior reviewer; "+newUser.ID+" is not senior")
+}
+
+// assignedReviewer loads an OPEN pull request together with one of its current reviewers.
+func (s *PRService) assignedReviewer(ctx context.Context, prID, userID, action string) (*domain.PullRequest, *domain.User, error) {
+	pr, err := s.prStorage.GetByID(ctx, prID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, notFound("pr not found")
+		}
+		return nil, nil, fmt.Errorf("pr not found: %w", err)
+	}
+	if err = requireOpen(pr, action); err != nil {
+		return nil, nil, err
+	}
+
+	if !isAssigned(*pr, userID) {
+		return nil, nil, conflict(ErrCodeNotAssigned, "reviewer is not assigned to this PR")
+	}
+
+	user, err := s.userStorage.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, notFound("user not found")
+		}
+		return nil, nil, fmt.Errorf("failed to get old reviewer info: %w", err)
+	}
+	return pr, user, nil
+}
+
+// noReplacementError explains why no replacement for oldUser could be picked on a pull request.
+func (s *PRService) noReplacementError(ctx context.Context, pr domain.PullRequest, oldUser domain.User) error {
+	author, err := s.userStorage.GetByID(ctx, pr.AuthorID)
+	if err != nil {
+		return fmt.Errorf("failed to get author: %w", err)
+	}
+	settings, err := s.teamStorage.GetSettings(ctx, author.TeamName)
+	if err != nil {
+		return fmt.Errorf("failed to get team settings: %w", err)
+	}
+
+	exclude := append([]string{pr.AuthorID, oldUser.ID}, pr.ReviewerIDs()...)
+	full, err := s.hasCandidatesAtCapacity(ctx, candidateTeams(oldUser.TeamName, *settings), exclude)
+	if err != nil {
+		return err
+	}
+	if full {
+		return conflict(ErrCodeCapacityExceeded, "all replacement candidates are at capacity")
+	}
+	return conflict(ErrCodeNoCandidate, "no active replacement candidate in team")
+}
+
 // CreateTeam creates a new team along with its members.
 func (s *PRService) CreateTeam(ctx context.Context, team domain.Team) (*domain.Team, error) {
 	if _, err := s.teamStorage.GetByName(ctx, team.Name); err == nil {
@@ -233,16 +345,45 @@ func (s *PRService) CreateTeam(ctx context.Context, team domain.Team) (*domain.T
 		return nil, err
 	}
 
+	for i := range team.Members {
+		if team.Members[i].Skills != nil {
+			team.Members[i].Skills = domain.NormalizeTags(team.Members[i].Skills)
+		}
+		// fields left out keep their current value for existing users, so only the schedule as given is checked
+		schedule := team.Members[i]
+		schedule.ApplyScheduleDefaults()
+		if err := validateSchedule(schedule); err != nil {
+			return nil, err
+		}
+	}
+
+	if team.Settings != nil {
+		team.Settings.TeamName = team.Name
+		if err := s.validateSettings(ctx, *team.Settings); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := s.teamStorage.Save(ctx, team); err != nil {
 		return nil, fmt.Errorf("failed to save team: %w", err)
 	}
 
+	if team.Settings != nil {
+		if _, err := s.UpdateTeamSettings(ctx, *team.Settings); err != nil {
+			return nil, err
+		}
+	}
+
 	for i, u := range team.Members {
 		u.TeamName = team.Name
 		if err := s.userStorage.Save(ctx, u); err != nil {
 			return nil, fmt.Errorf("failed to save user %s: %w", u.ID, err)
 		}
-		team.Members[i] = u
+		saved, err := s.userStorage.GetByID(ctx, u.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user %s: %w", u.ID, err)
+		}
+		team.Members[i] = *saved
 	}
 
 	return &team, nil
@@ -279,9 +420,73 @@ func (s *PRService) GetTeam(ctx context.Context, teamName string) (*domain.Team,
 	return team, nil
 }
 
-// SetUserActive sets the active status of a user.
-func (s *PRService) SetUserActive(ctx context.Context, userID string, isActive bool) (*domain.User, error) {
-	if err := s.userStorage.UpdateActivity(ctx, userID, isActive); err != nil {
+// SetUserActive sets the active status of a user. Deactivating a user moves their reviews on OPEN pull requests
+// to replacements picked as in Reassign, all in one transaction; reviews without a replacement are dropped.
+func (s *PRService) SetUserActive(ctx context.Context, userID string, isActive bool) (*domain.User, *domain.ReassignmentReport, error) {
+	user, err := s.userStorage.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, notFound("user not found")
+		}
+		return nil, nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err = s.userStorage.UpdateActivity(ctx, tx, userID, isActive); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, notFound("user not found")
+		}
+		return nil, nil, err
+	}
+
+	report := &domain.ReassignmentReport{
+		Reassigned: []domain.ReviewerReplacement{},
+		Unfilled:   []domain.ReviewerReplacement{},
+	}
+	if !isActive {
+		if report, err = s.reassignOpenReviews(ctx, tx, *user); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit tx: %w", err)
+	}
+
+	user.IsActive = isActive
+	return user, report, nil
+}
+
+// SetUserCapacity sets the maximum number of OPEN pull requests the user may review at once.
+// A nil limit makes the user follow their team's default again.
+func (s *PRService) SetUserCapacity(ctx context.Context, userID string, limit *int) (*domain.User, error) {
+	if limit != nil && *limit < 1 {
+		return nil, newServiceError(ErrCodeInvalidSettings, "max_open_reviews must be at least 1")
+	}
+
+	if err := s.userStorage.SetMaxOpenReviews(ctx, userID, limit); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, notFound("user not found")
+		}
+		return nil, err
+	}
+
+	return s.userStorage.GetByID(ctx, userID)
+}
+
+// SetUserRole changes the role of a user. Reviews already assigned are kept even if a team's senior policy
+// is no longer met.
+func (s *PRService) SetUserRole(ctx context.Context, userID string, role domain.UserRole) (*domain.User, error) {
+	if !role.IsValid() {
+		return nil, newServiceError(ErrCodeInvalidSettings, "unknown role "+string(role))
+	}
+
+	if err := s.userStorage.SetRole(ctx, userID, role); err != nil {
 		if errors.Is(err, storage.ErrNotFound) {
 			return nil, notFound("user not found")
 		}
@@ -291,6 +496,48 @@ func (s *PRService) SetUserActive(ctx context.Context, userID string, isActive b
 	return s.userStorage.GetByID(ctx, userID)
 }
 
+// UpdateUser changes the username, skill tags and working schedule of a user. Nil fields are left unchanged.
+func (s *PRService) UpdateUser(ctx context.Context, userID string, update domain.UserUpdate) (*domain.User, error) {
+	user, err := s.userStorage.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, notFound("user not found")
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if update.Username != nil {
+		user.Username = *update.Username
+	}
+	if update.Skills != nil {
+		user.Skills = domain.NormalizeTags(*update.Skills)
+	}
+	if update.TimeZone != nil {
+		user.TimeZone = *update.TimeZone
+	}
+	if update.WorkStartHour != nil {
+		user.WorkStartHour = *update.WorkStartHour
+	}
+	if update.WorkEndHour != nil {
+		user.WorkEndHour = *update.WorkEndHour
+	}
+	if update.WorkDays != nil {
+		user.WorkDays = *update.WorkDays
+	}
+	user.ApplyScheduleDefaults()
+	if err = validateSchedule(*user); err != nil {
+		return nil, err
+	}
+
+	if err = s.userStorage.UpdateProfile(ctx, *user); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, notFound("user not found")
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
 // GetUserReviews retrieves all pull requests assigned to a specific reviewer.
 func (s *PRService) GetUserReviews(ctx context.Context, reviewerID string) ([]domain.PullRequestShort, error) {
 	if _, err := s.userStorage.GetByID(ctx, reviewerID); err != nil {
@@ -318,32 +565,67 @@ func (s *PRService) GetUserReviews(ctx context.Context, reviewerID string) ([]do
 	return result, nil
 }
 
-// DeactivateTeam deactivates all users in a team and removes them from open pull requests.
-func (s *PRService) DeactivateTeam(ctx context.Context, teamName string) error {
+// DeactivateTeam deactivates all users in a team, removes them from open pull requests and backfills
+// their reviews from the author's team or its fallback teams. It returns the outcome per affected pull request.
+// In dry-run mode nothing is committed and the report only previews the impact.
+func (s *PRService) DeactivateTeam(ctx context.Context, teamName string, dryRun bool) ([]domain.PRReassignment, error) {
 	_, err := s.teamStorage.GetByName(ctx, teamName)
 	if err != nil {
 		if errors.Is(err, storage.ErrNotFound) {
-			return notFound("team not found")
+			return nil, notFound("team not found")
 		}
-		return err
+		return nil, err
+	}
+
+	members, err := s.userStorage.GetUsersByTeam(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	prIDs, err := s.prStorage.GetOpenIDsByReviewerTeam(ctx, teamName)
+	if err != nil {
+		return nil, err
 	}
 
 	// Transactional operation
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer func() { _ = tx.Rollback() }()
 
 	if err := s.userStorage.MassDeactivate(ctx, tx, teamName); err != nil {
-		return err
+		return nil, err
 	}
 
 	if err := s.prStorage.RemoveReviewersByTeam(ctx, tx, teamName); err != nil {
-		return err
+		return nil, err
 	}
 
-	return tx.Commit()
+	// replacements picked for earlier pull requests count towards load and capacity of later ones
+	ctx = withPendingReviews(ctx)
+	report := make([]domain.PRReassignment, 0, len(prIDs))
+	for _, prID := range prIDs {
+		result, err := s.backfillTeamReviews(ctx, tx, prID, members)
+		if err != nil {
+			return nil, err
+		}
+		report = append(report, *result)
+	}
+	err = s.publish(ctx, tx, domain.WebhookTeamDeactivated, map[string]interface{}{
+		"team_name":     teamName,
+		"pull_requests": report,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if dryRun {
+		return report, nil
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return report, nil
 }
 
 // GetStats retrieves system statistics related to pull requests.
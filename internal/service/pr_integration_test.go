@@ -3,7 +3,12 @@ package service
 import (
 	"context"
 	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	_ "github.com/lib/pq"
 	"github.com/neizhmak/avito-review-service/internal/domain"
@@ -88,7 +93,7 @@ func TestPRService_Merge_Happy(t *testing.T) {
 		t.Fatalf("failed to save pr: %v", err)
 	}
 
-	mergedPR, err := service.Merge(ctx, prID)
+	mergedPR, err := service.Merge(ctx, prID, false)
 	if err != nil {
 		t.Fatalf("first merge failed: %v", err)
 	}
@@ -138,7 +143,7 @@ func TestPRService_Reassign_Happy(t *testing.T) {
 		t.Fatalf("failed to save reviewer: %v", err)
 	}
 
-	newRevID, err := service.Reassign(ctx, prID, oldReviewerID)
+	newRevID, err := service.Reassign(ctx, prID, oldReviewerID, "")
 	if err != nil {
 		t.Fatalf("reassign failed: %v", err)
 	}
@@ -208,7 +213,7 @@ func TestPRService_Reassign_NotAssigned(t *testing.T) {
 	pr := domain.PullRequest{ID: prID, Title: "PR", AuthorID: authorID, Status: domain.PRStatusOpen}
 	testutil.SeedPR(t, prStorage, db, pr, assignedReviewer)
 
-	_, err := service.Reassign(ctx, prID, unassigned)
+	_, err := service.Reassign(ctx, prID, unassigned, "")
 	if err == nil {
 		t.Fatalf("expected not assigned error, got nil")
 	}
@@ -227,7 +232,7 @@ func TestPRService_Merge_NotFound(t *testing.T) {
 	teamStorage := postgres.NewTeamStorage(db)
 	service := NewPRService(prStorage, userStorage, teamStorage, db)
 
-	_, err := service.Merge(context.Background(), "missing-pr")
+	_, err := service.Merge(context.Background(), "missing-pr", false)
 	if err == nil {
 		t.Fatalf("expected not found error, got nil")
 	}
@@ -260,9 +265,13 @@ func TestPRService_DeactivateTeam_RemovesReviewers(t *testing.T) {
 	})
 	testutil.SeedPR(t, prStorage, db, domain.PullRequest{ID: prID, Title: "Deactivate", AuthorID: authorID}, reviewerID)
 
-	if err := service.DeactivateTeam(ctx, teamName); err != nil {
+	report, err := service.DeactivateTeam(ctx, teamName, false)
+	if err != nil {
 		t.Fatalf("deactivate team failed: %v", err)
 	}
+	if len(report) != 1 || len(report[0].Unfilled) != 1 {
+		t.Fatalf("expected one unfilled review, got %+v", report)
+	}
 
 	var isActive bool
 	if err := db.QueryRowContext(ctx, "SELECT is_active FROM users WHERE id = $1", reviewerID).Scan(&isActive); err != nil {
@@ -342,13 +351,16 @@ func TestPRService_SetUserActiveAndStats(t *testing.T) {
 	})
 	testutil.SeedPR(t, prStorage, db, domain.PullRequest{ID: prID, Title: "PR", AuthorID: authorID}, reviewerID)
 
-	updatedUser, err := service.SetUserActive(ctx, reviewerID, false)
+	updatedUser, report, err := service.SetUserActive(ctx, reviewerID, false)
 	if err != nil {
 		t.Fatalf("SetUserActive failed: %v", err)
 	}
 	if updatedUser.IsActive {
 		t.Fatalf("expected user to be inactive")
 	}
+	if len(report.Unfilled) != 1 || report.Unfilled[0].PRID != prID {
+		t.Fatalf("expected the only review to be unfilled, got %+v", report)
+	}
 
 	stats, err := service.GetStats(ctx)
 	if err != nil {
@@ -451,7 +463,7 @@ func TestPRService_Reassign_NoCandidate(t *testing.T) {
 	pr := domain.PullRequest{ID: prID, Title: "No Candidate", AuthorID: authorID, Status: domain.PRStatusOpen}
 	testutil.SeedPR(t, prStorage, db, pr, reviewerID)
 
-	_, err := service.Reassign(ctx, prID, reviewerID)
+	_, err := service.Reassign(ctx, prID, reviewerID, "")
 	if err == nil {
 		t.Fatalf("expected no candidate error")
 	}
@@ -484,7 +496,7 @@ func TestPRService_Reassign_OnMerged(t *testing.T) {
 	pr := domain.PullRequest{ID: prID, Title: "PR", AuthorID: authorID, Status: domain.PRStatusMerged}
 	testutil.SeedPR(t, prStorage, db, pr, reviewerID)
 
-	_, err := service.Reassign(ctx, prID, reviewerID)
+	_, err := service.Reassign(ctx, prID, reviewerID, "")
 	if err == nil {
 		t.Fatalf("expected merged error")
 	}
@@ -554,7 +566,7 @@ func TestPRService_DeactivateTeam_NotFound(t *testing.T) {
 		postgres.NewTeamStorage(db),
 		db,
 	)
-	err := service.DeactivateTeam(context.Background(), "missing-team")
+	_, err := service.DeactivateTeam(context.Background(), "missing-team", false)
 	if err == nil {
 		t.Fatalf("expected error")
 	}
@@ -563,3 +575,1755 @@ func TestPRService_DeactivateTeam_NotFound(t *testing.T) {
 		t.Fatalf("expected ErrCodeNotFound, got %v", err)
 	}
 }
+
+func TestPRService_Create_RoundRobinStrategy(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	teamStorage := postgres.NewTeamStorage(db)
+	userStorage := postgres.NewUserStorage(db)
+	prStorage := postgres.NewPullRequestStorage(db)
+	service := NewPRService(prStorage, userStorage, teamStorage, db)
+	ctx := context.Background()
+
+	teamName := "rr-team"
+	testutil.CleanupTeamData(t, db, teamName)
+
+	settings := domain.DefaultTeamSettings(teamName)
+	settings.ReviewerStrategy = domain.StrategyRoundRobin
+
+	if _, err := service.CreateTeam(ctx, domain.Team{
+		Name:     teamName,
+		Settings: &settings,
+		Members: []domain.User{
+			{ID: "rr-author", Username: "Author", IsActive: true},
+			{ID: "rr-a", Username: "A", IsActive: true},
+			{ID: "rr-b", Username: "B", IsActive: true},
+			{ID: "rr-c", Username: "C", IsActive: true},
+		},
+	}); err != nil {
+		t.Fatalf("CreateTeam failed: %v", err)
+	}
+
+	first, err := service.Create(ctx, domain.PullRequest{ID: "rr-pr-1", Title: "One", AuthorID: "rr-author"})
+	if err != nil {
+		t.Fatalf("first Create failed: %v", err)
+	}
+	second, err := service.Create(ctx, domain.PullRequest{ID: "rr-pr-2", Title: "Two", AuthorID: "rr-author"})
+	if err != nil {
+		t.Fatalf("second Create failed: %v", err)
+	}
+
+	if ids := first.ReviewerIDs(); ids[0] != "rr-a" || ids[1] != "rr-b" {
+		t.Fatalf("expected [rr-a rr-b], got %v", ids)
+	}
+	if ids := second.ReviewerIDs(); ids[0] != "rr-c" || ids[1] != "rr-a" {
+		t.Fatalf("expected rotation to continue with [rr-c rr-a], got %v", ids)
+	}
+
+	// the cursor is stored with the team, so another instance continues the same rotation
+	restarted := NewPRService(prStorage, userStorage, teamStorage, db)
+	third, err := restarted.Create(ctx, domain.PullRequest{ID: "rr-pr-3", Title: "Three", AuthorID: "rr-author"})
+	if err != nil {
+		t.Fatalf("third Create failed: %v", err)
+	}
+	if ids := third.ReviewerIDs(); ids[0] != "rr-b" || ids[1] != "rr-c" {
+		t.Fatalf("expected rotation to continue with [rr-b rr-c] after a restart, got %v", ids)
+	}
+}
+
+func TestPRService_CreateTeam_UnknownStrategy(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	service := NewPRService(
+		postgres.NewPullRequestStorage(db),
+		postgres.NewUserStorage(db),
+		postgres.NewTeamStorage(db),
+		db,
+	)
+
+	teamName := "bad-strategy-team"
+	testutil.CleanupTeamData(t, db, teamName)
+
+	settings := domain.DefaultTeamSettings(teamName)
+	settings.ReviewerStrategy = "coin_flip"
+
+	_, err := service.CreateTeam(context.Background(), domain.Team{Name: teamName, Settings: &settings})
+	var svcErr *ServiceError
+	if !errors.As(err, &svcErr) || svcErr.Code != ErrCodeUnknownStrategy {
+		t.Fatalf("expected ErrCodeUnknownStrategy, got %v", err)
+	}
+}
+
+func TestPRService_Create_PrefersLeastLoaded(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	teamStorage := postgres.NewTeamStorage(db)
+	userStorage := postgres.NewUserStorage(db)
+	prStorage := postgres.NewPullRequestStorage(db)
+	service := NewPRService(prStorage, userStorage, teamStorage, db)
+	ctx := context.Background()
+
+	teamName := "load-team"
+	testutil.CleanupTeamData(t, db, teamName)
+
+	testutil.SeedTeam(t, teamStorage, userStorage, teamName, []domain.User{
+		{ID: "ll-author", Username: "Author", IsActive: true},
+		{ID: "ll-busy", Username: "Busy", IsActive: true},
+		{ID: "ll-idle-1", Username: "Idle1", IsActive: true},
+		{ID: "ll-idle-2", Username: "Idle2", IsActive: true},
+	})
+	testutil.SeedPR(t, prStorage, db, domain.PullRequest{ID: "ll-old", Title: "Old", AuthorID: "ll-author"}, "ll-busy")
+
+	created, err := service.Create(ctx, domain.PullRequest{ID: "ll-new", Title: "New", AuthorID: "ll-author"})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	for _, id := range created.ReviewerIDs() {
+		if id == "ll-busy" {
+			t.Fatalf("expected idle reviewers to be preferred, got %v", created.Reviewers)
+		}
+	}
+}
+
+func TestPRService_TeamSettings(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	teamStorage := postgres.NewTeamStorage(db)
+	userStorage := postgres.NewUserStorage(db)
+	prStorage := postgres.NewPullRequestStorage(db)
+	service := NewPRService(prStorage, userStorage, teamStorage, db)
+	ctx := context.Background()
+
+	teamName := "settings-team"
+	testutil.CleanupTeamData(t, db, teamName)
+
+	testutil.SeedTeam(t, teamStorage, userStorage, teamName, []domain.User{
+		{ID: "st-author", Username: "Author", IsActive: true},
+		{ID: "st-rev-1", Username: "Rev1", IsActive: true},
+		{ID: "st-rev-2", Username: "Rev2", IsActive: true},
+		{ID: "st-rev-3", Username: "Rev3", IsActive: true},
+	})
+
+	settings, err := service.GetTeamSettings(ctx, teamName)
+	if err != nil {
+		t.Fatalf("GetTeamSettings failed: %v", err)
+	}
+	if settings.ReviewerCount != 2 || settings.ReviewerStrategy != domain.DefaultReviewerStrategy {
+		t.Fatalf("expected default settings, got %+v", settings)
+	}
+
+	settings.ReviewerCount = 3
+	settings.MinReviewers = 3
+	if _, err = service.UpdateTeamSettings(ctx, *settings); err != nil {
+		t.Fatalf("UpdateTeamSettings failed: %v", err)
+	}
+
+	created, err := service.Create(ctx, domain.PullRequest{ID: "st-pr-1", Title: "Three", AuthorID: "st-author"})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if len(created.Reviewers) != 3 {
+		t.Fatalf("expected 3 reviewers, got %v", created.Reviewers)
+	}
+
+	if _, _, err = service.SetUserActive(ctx, "st-rev-3", false); err != nil {
+		t.Fatalf("SetUserActive failed: %v", err)
+	}
+	_, err = service.Create(ctx, domain.PullRequest{ID: "st-pr-2", Title: "Not enough", AuthorID: "st-author"})
+	var svcErr *ServiceError
+	if !errors.As(err, &svcErr) || svcErr.Code != ErrCodeNoCandidate {
+		t.Fatalf("expected ErrCodeNoCandidate below min_reviewers, got %v", err)
+	}
+
+	settings.MinReviewers = 4
+	_, err = service.UpdateTeamSettings(ctx, *settings)
+	if !errors.As(err, &svcErr) || svcErr.Code != ErrCodeInvalidSettings {
+		t.Fatalf("expected ErrCodeInvalidSettings, got %v", err)
+	}
+}
+
+func TestPRService_CrossTeamFallback(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	teamStorage := postgres.NewTeamStorage(db)
+	userStorage := postgres.NewUserStorage(db)
+	prStorage := postgres.NewPullRequestStorage(db)
+	service := NewPRService(prStorage, userStorage, teamStorage, db)
+	ctx := context.Background()
+
+	smallTeam := "fallback-small"
+	helperTeam := "fallback-helper"
+	testutil.CleanupTeamData(t, db, smallTeam)
+	testutil.CleanupTeamData(t, db, helperTeam)
+
+	testutil.SeedTeam(t, teamStorage, userStorage, helperTeam, []domain.User{
+		{ID: "fb-helper-1", Username: "Helper1", IsActive: true},
+		{ID: "fb-helper-2", Username: "Helper2", IsActive: true},
+	})
+	testutil.SeedTeam(t, teamStorage, userStorage, smallTeam, []domain.User{
+		{ID: "fb-author", Username: "Author", IsActive: true},
+		{ID: "fb-teammate", Username: "Teammate", IsActive: true},
+	})
+
+	settings := domain.DefaultTeamSettings(smallTeam)
+	settings.AllowCrossTeamFallback = true
+	settings.FallbackTeams = []string{helperTeam}
+	if _, err := service.UpdateTeamSettings(ctx, settings); err != nil {
+		t.Fatalf("UpdateTeamSettings failed: %v", err)
+	}
+
+	created, err := service.Create(ctx, domain.PullRequest{ID: "fb-pr", Title: "Small team", AuthorID: "fb-author"})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if len(created.Reviewers) != 2 {
+		t.Fatalf("expected 2 reviewers, got %v", created.Reviewers)
+	}
+	fallbacks := 0
+	for _, r := range created.Reviewers {
+		if r.FallbackTeam == helperTeam {
+			fallbacks++
+		}
+	}
+	if fallbacks != 1 {
+		t.Fatalf("expected one fallback reviewer from %s, got %+v", helperTeam, created.Reviewers)
+	}
+
+	// the only teammate can still be replaced by someone from the fallback team
+	newID, err := service.Reassign(ctx, "fb-pr", "fb-teammate", "")
+	if err != nil {
+		t.Fatalf("Reassign failed: %v", err)
+	}
+	pr, err := service.GetPR(ctx, "fb-pr")
+	if err != nil {
+		t.Fatalf("GetPR failed: %v", err)
+	}
+	for _, r := range pr.Reviewers {
+		if r.FallbackTeam != helperTeam {
+			t.Fatalf("expected both reviewers from fallback team after reassigning to %s, got %+v", newID, pr.Reviewers)
+		}
+	}
+}
+
+func TestPRService_Create_CodeOwnerGuaranteed(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	teamStorage := postgres.NewTeamStorage(db)
+	userStorage := postgres.NewUserStorage(db)
+	prStorage := postgres.NewPullRequestStorage(db)
+	service := NewPRService(prStorage, userStorage, teamStorage, db)
+	ctx := context.Background()
+
+	authorTeam := "co-authors"
+	ownerTeam := "co-billing"
+	testutil.CleanupTeamData(t, db, authorTeam)
+	testutil.CleanupTeamData(t, db, ownerTeam)
+
+	testutil.SeedTeam(t, teamStorage, userStorage, authorTeam, []domain.User{
+		{ID: "co-author", Username: "Author", IsActive: true},
+		{ID: "co-mate-1", Username: "Mate1", IsActive: true},
+		{ID: "co-mate-2", Username: "Mate2", IsActive: true},
+	})
+	testutil.SeedTeam(t, teamStorage, userStorage, ownerTeam, []domain.User{
+		{ID: "co-owner", Username: "Owner", IsActive: true},
+	})
+
+	if _, err := service.SetCodeOwnerRules(ctx, []domain.CodeOwnerRule{
+		{Pattern: "/billing/", Teams: []string{ownerTeam}},
+	}); err != nil {
+		t.Fatalf("SetCodeOwnerRules failed: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = service.SetCodeOwnerRules(ctx, []domain.CodeOwnerRule{})
+	})
+
+	created, err := service.Create(ctx, domain.PullRequest{
+		ID:           "co-pr",
+		Title:        "Billing fix",
+		AuthorID:     "co-author",
+		ChangedFiles: []string{"billing/invoice.go", "README.md"},
+	})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if len(created.Reviewers) != 2 || created.Reviewers[0].UserID != "co-owner" {
+		t.Fatalf("expected owner plus one teammate, got %v", created.Reviewers)
+	}
+
+	stored, err := service.GetPR(ctx, "co-pr")
+	if err != nil {
+		t.Fatalf("GetPR failed: %v", err)
+	}
+	if len(stored.ChangedFiles) != 2 {
+		t.Fatalf("expected changed files to be stored, got %v", stored.ChangedFiles)
+	}
+
+	_, err = service.SetCodeOwnerRules(ctx, []domain.CodeOwnerRule{{Pattern: "/billing/"}})
+	var svcErr *ServiceError
+	if !errors.As(err, &svcErr) || svcErr.Code != ErrCodeInvalidRule {
+		t.Fatalf("expected ErrCodeInvalidRule for rule without owners, got %v", err)
+	}
+}
+
+func TestPRService_SubmitReview(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	teamStorage := postgres.NewTeamStorage(db)
+	userStorage := postgres.NewUserStorage(db)
+	prStorage := postgres.NewPullRequestStorage(db)
+	service := NewPRService(prStorage, userStorage, teamStorage, db)
+	ctx := context.Background()
+
+	teamName := "review-state-team"
+	testutil.CleanupTeamData(t, db, teamName)
+
+	testutil.SeedTeam(t, teamStorage, userStorage, teamName, []domain.User{
+		{ID: "rs-author", Username: "Author", IsActive: true},
+		{ID: "rs-reviewer", Username: "Reviewer", IsActive: true},
+		{ID: "rs-outsider", Username: "Outsider", IsActive: true},
+	})
+	testutil.SeedPR(t, prStorage, db, domain.PullRequest{ID: "rs-pr", Title: "Review me", AuthorID: "rs-author"}, "rs-reviewer")
+
+	pr, err := service.SubmitReview(ctx, "rs-pr", "rs-reviewer", domain.ReviewStateApproved)
+	if err != nil {
+		t.Fatalf("SubmitReview failed: %v", err)
+	}
+	if len(pr.Reviewers) != 1 || pr.Reviewers[0].State != domain.ReviewStateApproved || pr.Reviewers[0].StateUpdatedAt == nil {
+		t.Fatalf("expected approved review with timestamp, got %+v", pr.Reviewers)
+	}
+
+	_, err = service.SubmitReview(ctx, "rs-pr", "rs-outsider", domain.ReviewStateApproved)
+	var svcErr *ServiceError
+	if !errors.As(err, &svcErr) || svcErr.Code != ErrCodeNotAssigned {
+		t.Fatalf("expected ErrCodeNotAssigned, got %v", err)
+	}
+}
+
+func TestPRService_Merge_Policy(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	teamStorage := postgres.NewTeamStorage(db)
+	userStorage := postgres.NewUserStorage(db)
+	prStorage := postgres.NewPullRequestStorage(db)
+	service := NewPRService(prStorage, userStorage, teamStorage, db)
+	ctx := context.Background()
+
+	teamName := "merge-policy-team"
+	testutil.CleanupTeamData(t, db, teamName)
+
+	testutil.SeedTeam(t, teamStorage, userStorage, teamName, []domain.User{
+		{ID: "mp-author", Username: "Author", IsActive: true},
+		{ID: "mp-rev-1", Username: "Rev1", IsActive: true},
+		{ID: "mp-rev-2", Username: "Rev2", IsActive: true},
+	})
+	testutil.SeedPR(t, prStorage, db, domain.PullRequest{ID: "mp-pr-1", Title: "Gated", AuthorID: "mp-author"}, "mp-rev-1", "mp-rev-2")
+	testutil.SeedPR(t, prStorage, db, domain.PullRequest{ID: "mp-pr-2", Title: "Forced", AuthorID: "mp-author"}, "mp-rev-1")
+
+	settings := domain.DefaultTeamSettings(teamName)
+	settings.RequiredApprovals = 1
+	settings.BlockOnChangesRequested = true
+	if _, err := service.UpdateTeamSettings(ctx, settings); err != nil {
+		t.Fatalf("UpdateTeamSettings failed: %v", err)
+	}
+
+	var svcErr *ServiceError
+	if _, err := service.Merge(ctx, "mp-pr-1", false); !errors.As(err, &svcErr) || svcErr.Code != ErrCodeMergeBlocked {
+		t.Fatalf("expected ErrCodeMergeBlocked without approvals, got %v", err)
+	}
+
+	if _, err := service.SubmitReview(ctx, "mp-pr-1", "mp-rev-1", domain.ReviewStateApproved); err != nil {
+		t.Fatalf("SubmitReview failed: %v", err)
+	}
+	if _, err := service.SubmitReview(ctx, "mp-pr-1", "mp-rev-2", domain.ReviewStateChangesRequested); err != nil {
+		t.Fatalf("SubmitReview failed: %v", err)
+	}
+	if _, err := service.Merge(ctx, "mp-pr-1", false); !errors.As(err, &svcErr) || svcErr.Code != ErrCodeMergeBlocked {
+		t.Fatalf("expected ErrCodeMergeBlocked with changes requested, got %v", err)
+	}
+
+	if _, err := service.SubmitReview(ctx, "mp-pr-1", "mp-rev-2", domain.ReviewStateDismissed); err != nil {
+		t.Fatalf("SubmitReview failed: %v", err)
+	}
+	merged, err := service.Merge(ctx, "mp-pr-1", false)
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if merged.ForceMerged {
+		t.Fatalf("expected regular merge, got force merged")
+	}
+
+	if _, err = service.Merge(ctx, "mp-pr-2", true); err != nil {
+		t.Fatalf("forced Merge failed: %v", err)
+	}
+	stored, err := service.GetPR(ctx, "mp-pr-2")
+	if err != nil {
+		t.Fatalf("GetPR failed: %v", err)
+	}
+	if stored.Status != domain.PRStatusMerged || !stored.ForceMerged {
+		t.Fatalf("expected force merged PR, got %+v", stored)
+	}
+}
+
+func TestPRService_Lifecycle(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	teamStorage := postgres.NewTeamStorage(db)
+	userStorage := postgres.NewUserStorage(db)
+	prStorage := postgres.NewPullRequestStorage(db)
+	service := NewPRService(prStorage, userStorage, teamStorage, db)
+	ctx := context.Background()
+
+	teamName := "lifecycle-team"
+	testutil.CleanupTeamData(t, db, teamName)
+
+	testutil.SeedTeam(t, teamStorage, userStorage, teamName, []domain.User{
+		{ID: "lc-author", Username: "Author", IsActive: true},
+		{ID: "lc-rev-1", Username: "Rev1", IsActive: true},
+		{ID: "lc-rev-2", Username: "Rev2", IsActive: true},
+	})
+
+	draft, err := service.Create(ctx, domain.PullRequest{ID: "lc-pr", Title: "WIP", AuthorID: "lc-author", Status: domain.PRStatusDraft})
+	if err != nil {
+		t.Fatalf("Create draft failed: %v", err)
+	}
+	if draft.Status != domain.PRStatusDraft || len(draft.Reviewers) != 0 {
+		t.Fatalf("expected draft without reviewers, got %+v", draft)
+	}
+
+	var svcErr *ServiceError
+	if _, err = service.Merge(ctx, "lc-pr", false); !errors.As(err, &svcErr) || svcErr.Code != ErrCodeInvalidStatus {
+		t.Fatalf("expected ErrCodeInvalidStatus merging draft, got %v", err)
+	}
+	if _, err = service.Reopen(ctx, "lc-pr"); !errors.As(err, &svcErr) || svcErr.Code != ErrCodeInvalidStatus {
+		t.Fatalf("expected ErrCodeInvalidStatus reopening draft, got %v", err)
+	}
+
+	ready, err := service.Ready(ctx, "lc-pr")
+	if err != nil {
+		t.Fatalf("Ready failed: %v", err)
+	}
+	if ready.Status != domain.PRStatusOpen || len(ready.Reviewers) != 2 {
+		t.Fatalf("expected open PR with 2 reviewers, got %+v", ready)
+	}
+
+	closed, err := service.Close(ctx, "lc-pr")
+	if err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if closed.Status != domain.PRStatusClosed || closed.ClosedAt == nil {
+		t.Fatalf("expected closed PR with closedAt, got %+v", closed)
+	}
+
+	counts, err := prStorage.GetOpenReviewCountsByTeam(ctx, teamName)
+	if err != nil {
+		t.Fatalf("GetOpenReviewCountsByTeam failed: %v", err)
+	}
+	if counts["lc-rev-1"] != 0 || counts["lc-rev-2"] != 0 {
+		t.Fatalf("expected closed PR not to count as load, got %v", counts)
+	}
+
+	reopened, err := service.Reopen(ctx, "lc-pr")
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	if reopened.Status != domain.PRStatusOpen || reopened.ClosedAt != nil || len(reopened.Reviewers) != 2 {
+		t.Fatalf("expected reopened PR with its reviewers, got %+v", reopened)
+	}
+}
+
+func TestPRService_SetUserActive_ReassignsOpenReviews(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	teamStorage := postgres.NewTeamStorage(db)
+	userStorage := postgres.NewUserStorage(db)
+	prStorage := postgres.NewPullRequestStorage(db)
+	service := NewPRService(prStorage, userStorage, teamStorage, db)
+	ctx := context.Background()
+
+	teamName := "leave-team"
+	testutil.CleanupTeamData(t, db, teamName)
+
+	testutil.SeedTeam(t, teamStorage, userStorage, teamName, []domain.User{
+		{ID: "lv-author", Username: "Author", IsActive: true},
+		{ID: "lv-leaving", Username: "Leaving", IsActive: true},
+		{ID: "lv-other", Username: "Other", IsActive: true},
+		{ID: "lv-spare", Username: "Spare", IsActive: true},
+	})
+	testutil.SeedPR(t, prStorage, db, domain.PullRequest{ID: "lv-pr-open", Title: "Open", AuthorID: "lv-author"}, "lv-leaving", "lv-other")
+	testutil.SeedPR(t, prStorage, db, domain.PullRequest{ID: "lv-pr-full", Title: "Full", AuthorID: "lv-spare"}, "lv-leaving", "lv-other", "lv-author")
+	testutil.SeedPR(t, prStorage, db, domain.PullRequest{ID: "lv-pr-merged", Title: "Merged", AuthorID: "lv-author", Status: domain.PRStatusMerged}, "lv-leaving")
+
+	_, report, err := service.SetUserActive(ctx, "lv-leaving", false)
+	if err != nil {
+		t.Fatalf("SetUserActive failed: %v", err)
+	}
+
+	if len(report.Reassigned) != 1 || report.Reassigned[0].PRID != "lv-pr-open" || report.Reassigned[0].NewReviewerID != "lv-spare" {
+		t.Fatalf("expected lv-pr-open moved to lv-spare, got %+v", report.Reassigned)
+	}
+	if len(report.Unfilled) != 1 || report.Unfilled[0].PRID != "lv-pr-full" {
+		t.Fatalf("expected lv-pr-full unfilled, got %+v", report.Unfilled)
+	}
+
+	full, err := service.GetPR(ctx, "lv-pr-full")
+	if err != nil {
+		t.Fatalf("GetPR failed: %v", err)
+	}
+	for _, id := range full.ReviewerIDs() {
+		if id == "lv-leaving" {
+			t.Fatalf("expected deactivated reviewer removed, got %v", full.ReviewerIDs())
+		}
+	}
+
+	merged, err := service.GetPR(ctx, "lv-pr-merged")
+	if err != nil {
+		t.Fatalf("GetPR failed: %v", err)
+	}
+	if len(merged.Reviewers) != 1 {
+		t.Fatalf("expected merged PR untouched, got %v", merged.ReviewerIDs())
+	}
+}
+
+func TestPRService_SetUserActive_SpreadsReplacements(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	teamStorage := postgres.NewTeamStorage(db)
+	userStorage := postgres.NewUserStorage(db)
+	prStorage := postgres.NewPullRequestStorage(db)
+	service := NewPRService(prStorage, userStorage, teamStorage, db)
+	ctx := context.Background()
+
+	teamName := "spread-team"
+	capTeam := "spread-cap-team"
+	testutil.CleanupTeamData(t, db, teamName)
+	testutil.CleanupTeamData(t, db, capTeam)
+
+	testutil.SeedTeam(t, teamStorage, userStorage, teamName, []domain.User{
+		{ID: "sp-author", Username: "Author", IsActive: true},
+		{ID: "sp-leaving", Username: "Leaving", IsActive: true},
+		{ID: "sp-idle", Username: "Idle", IsActive: true},
+		{ID: "sp-busy-1", Username: "Busy1", IsActive: true},
+		{ID: "sp-busy-2", Username: "Busy2", IsActive: true},
+	})
+	testutil.SeedPR(t, prStorage, db, domain.PullRequest{ID: "sp-pr-busy", Title: "Busy", AuthorID: "sp-author"}, "sp-busy-1", "sp-busy-2")
+	for _, id := range []string{"sp-pr-1", "sp-pr-2", "sp-pr-3"} {
+		testutil.SeedPR(t, prStorage, db, domain.PullRequest{ID: id, Title: id, AuthorID: "sp-author"}, "sp-leaving")
+	}
+
+	_, report, err := service.SetUserActive(ctx, "sp-leaving", false)
+	if err != nil {
+		t.Fatalf("SetUserActive failed: %v", err)
+	}
+	if len(report.Reassigned) != 3 {
+		t.Fatalf("expected three reassigned reviews, got %+v", report)
+	}
+	picks := map[string]int{}
+	for _, r := range report.Reassigned {
+		picks[r.NewReviewerID]++
+	}
+	if picks["sp-idle"] == 0 || picks["sp-idle"] > 2 || len(picks) < 2 {
+		t.Fatalf("expected replacements spread by load, got %v", picks)
+	}
+
+	testutil.SeedTeam(t, teamStorage, userStorage, capTeam, []domain.User{
+		{ID: "spc-author", Username: "Author", IsActive: true},
+		{ID: "spc-leaving", Username: "Leaving", IsActive: true},
+		{ID: "spc-free", Username: "Free", IsActive: true},
+		{ID: "spc-busy", Username: "Busy", IsActive: true},
+	})
+	settings := domain.DefaultTeamSettings(capTeam)
+	settings.MaxOpenReviews = 1
+	if _, err = service.UpdateTeamSettings(ctx, settings); err != nil {
+		t.Fatalf("UpdateTeamSettings failed: %v", err)
+	}
+	testutil.SeedPR(t, prStorage, db, domain.PullRequest{ID: "spc-pr-busy", Title: "Busy", AuthorID: "spc-author"}, "spc-busy")
+	testutil.SeedPR(t, prStorage, db, domain.PullRequest{ID: "spc-pr-1", Title: "One", AuthorID: "spc-author"}, "spc-leaving")
+	testutil.SeedPR(t, prStorage, db, domain.PullRequest{ID: "spc-pr-2", Title: "Two", AuthorID: "spc-author"}, "spc-leaving")
+
+	_, report, err = service.SetUserActive(ctx, "spc-leaving", false)
+	if err != nil {
+		t.Fatalf("SetUserActive failed: %v", err)
+	}
+	if len(report.Reassigned) != 1 || report.Reassigned[0].NewReviewerID != "spc-free" || len(report.Unfilled) != 1 {
+		t.Fatalf("expected spc-free to take one review within capacity, got %+v", report)
+	}
+}
+
+func TestPRService_DeactivateTeam_Backfills(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	teamStorage := postgres.NewTeamStorage(db)
+	userStorage := postgres.NewUserStorage(db)
+	prStorage := postgres.NewPullRequestStorage(db)
+	service := NewPRService(prStorage, userStorage, teamStorage, db)
+	ctx := context.Background()
+
+	leavingTeam := "backfill-leaving"
+	authorTeam := "backfill-authors"
+	testutil.CleanupTeamData(t, db, leavingTeam)
+	testutil.CleanupTeamData(t, db, authorTeam)
+
+	testutil.SeedTeam(t, teamStorage, userStorage, leavingTeam, []domain.User{
+		{ID: "bf-leaving-1", Username: "Leaving1", IsActive: true},
+		{ID: "bf-leaving-2", Username: "Leaving2", IsActive: true},
+	})
+	testutil.SeedTeam(t, teamStorage, userStorage, authorTeam, []domain.User{
+		{ID: "bf-author", Username: "Author", IsActive: true},
+		{ID: "bf-spare", Username: "Spare", IsActive: true},
+	})
+	testutil.SeedPR(t, prStorage, db, domain.PullRequest{ID: "bf-pr", Title: "Backfill", AuthorID: "bf-author"}, "bf-leaving-1", "bf-leaving-2")
+
+	preview, err := service.DeactivateTeam(ctx, leavingTeam, true)
+	if err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	if len(preview) != 1 || len(preview[0].Reassigned) != 1 || len(preview[0].Unfilled) != 1 {
+		t.Fatalf("expected one replacement and one gap, got %+v", preview)
+	}
+	if preview[0].Reassigned[0].NewReviewerID != "bf-spare" {
+		t.Fatalf("expected bf-spare as replacement, got %+v", preview[0].Reassigned)
+	}
+
+	pr, err := service.GetPR(ctx, "bf-pr")
+	if err != nil {
+		t.Fatalf("GetPR failed: %v", err)
+	}
+	if ids := pr.ReviewerIDs(); len(ids) != 2 || ids[0] == "bf-spare" || ids[1] == "bf-spare" {
+		t.Fatalf("expected dry run to leave reviewers untouched, got %v", ids)
+	}
+
+	if _, err = service.DeactivateTeam(ctx, leavingTeam, false); err != nil {
+		t.Fatalf("deactivate team failed: %v", err)
+	}
+	pr, err = service.GetPR(ctx, "bf-pr")
+	if err != nil {
+		t.Fatalf("GetPR failed: %v", err)
+	}
+	if ids := pr.ReviewerIDs(); len(ids) != 1 || ids[0] != "bf-spare" {
+		t.Fatalf("expected only bf-spare after backfill, got %v", ids)
+	}
+}
+
+func TestPRService_DeactivateTeam_RespectsCapacity(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	teamStorage := postgres.NewTeamStorage(db)
+	userStorage := postgres.NewUserStorage(db)
+	prStorage := postgres.NewPullRequestStorage(db)
+	service := NewPRService(prStorage, userStorage, teamStorage, db)
+	ctx := context.Background()
+
+	leavingTeam := "backfill-cap-leaving"
+	authorTeam := "backfill-cap-authors"
+	testutil.CleanupTeamData(t, db, leavingTeam)
+	testutil.CleanupTeamData(t, db, authorTeam)
+
+	testutil.SeedTeam(t, teamStorage, userStorage, leavingTeam, []domain.User{
+		{ID: "bfc-leaving", Username: "Leaving", IsActive: true},
+	})
+	testutil.SeedTeam(t, teamStorage, userStorage, authorTeam, []domain.User{
+		{ID: "bfc-author", Username: "Author", IsActive: true},
+		{ID: "bfc-spare", Username: "Spare", IsActive: true},
+	})
+	settings := domain.DefaultTeamSettings(authorTeam)
+	settings.MaxOpenReviews = 2
+	if _, err := service.UpdateTeamSettings(ctx, settings); err != nil {
+		t.Fatalf("UpdateTeamSettings failed: %v", err)
+	}
+	for _, id := range []string{"bfc-pr-1", "bfc-pr-2", "bfc-pr-3"} {
+		testutil.SeedPR(t, prStorage, db, domain.PullRequest{ID: id, Title: id, AuthorID: "bfc-author"}, "bfc-leaving")
+	}
+
+	report, err := service.DeactivateTeam(ctx, leavingTeam, false)
+	if err != nil {
+		t.Fatalf("deactivate team failed: %v", err)
+	}
+	reassigned, unfilled := 0, 0
+	for _, r := range report {
+		reassigned += len(r.Reassigned)
+		unfilled += len(r.Unfilled)
+	}
+	if reassigned != 2 || unfilled != 1 {
+		t.Fatalf("expected bfc-spare to take two reviews up to capacity, got %+v", report)
+	}
+}
+
+func TestPRService_Unavailability(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	teamStorage := postgres.NewTeamStorage(db)
+	userStorage := postgres.NewUserStorage(db)
+	prStorage := postgres.NewPullRequestStorage(db)
+	service := NewPRService(prStorage, userStorage, teamStorage, db)
+	ctx := context.Background()
+
+	teamName := "vacation-team"
+	testutil.CleanupTeamData(t, db, teamName)
+
+	testutil.SeedTeam(t, teamStorage, userStorage, teamName, []domain.User{
+		{ID: "vc-author", Username: "Author", IsActive: true},
+		{ID: "vc-away", Username: "Away", IsActive: true},
+		{ID: "vc-later", Username: "Later", IsActive: true},
+	})
+
+	now := time.Now()
+	away, err := service.AddUnavailability(ctx, domain.Unavailability{
+		UserID: "vc-away", StartsAt: now.Add(-time.Hour), EndsAt: now.Add(24 * time.Hour), Reason: "vacation",
+	})
+	if err != nil {
+		t.Fatalf("AddUnavailability failed: %v", err)
+	}
+	if _, err = service.AddUnavailability(ctx, domain.Unavailability{
+		UserID: "vc-later", StartsAt: now.Add(24 * time.Hour), EndsAt: now.Add(48 * time.Hour),
+	}); err != nil {
+		t.Fatalf("AddUnavailability failed: %v", err)
+	}
+
+	var svcErr *ServiceError
+	_, err = service.AddUnavailability(ctx, domain.Unavailability{UserID: "vc-away", StartsAt: now, EndsAt: now.Add(-time.Hour)})
+	if !errors.As(err, &svcErr) || svcErr.Code != ErrCodeInvalidPeriod {
+		t.Fatalf("expected ErrCodeInvalidPeriod, got %v", err)
+	}
+
+	created, err := service.Create(ctx, domain.PullRequest{ID: "vc-pr-1", Title: "While away", AuthorID: "vc-author"})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if ids := created.ReviewerIDs(); len(ids) != 1 || ids[0] != "vc-later" {
+		t.Fatalf("expected only vc-later to be picked, got %v", ids)
+	}
+
+	if err = service.DeleteUnavailability(ctx, "vc-away", away.ID); err != nil {
+		t.Fatalf("DeleteUnavailability failed: %v", err)
+	}
+	periods, err := service.GetUnavailability(ctx, "vc-away")
+	if err != nil {
+		t.Fatalf("GetUnavailability failed: %v", err)
+	}
+	if len(periods) != 0 {
+		t.Fatalf("expected no periods after delete, got %+v", periods)
+	}
+}
+
+func TestPRService_Capacity(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	teamStorage := postgres.NewTeamStorage(db)
+	userStorage := postgres.NewUserStorage(db)
+	prStorage := postgres.NewPullRequestStorage(db)
+	service := NewPRService(prStorage, userStorage, teamStorage, db)
+	ctx := context.Background()
+
+	teamName := "capacity-team"
+	testutil.CleanupTeamData(t, db, teamName)
+
+	testutil.SeedTeam(t, teamStorage, userStorage, teamName, []domain.User{
+		{ID: "cap-author", Username: "Author", IsActive: true},
+		{ID: "cap-busy", Username: "Busy", IsActive: true},
+		{ID: "cap-free", Username: "Free", IsActive: true},
+	})
+	testutil.SeedPR(t, prStorage, db, domain.PullRequest{ID: "cap-existing", Title: "Existing", AuthorID: "cap-author"}, "cap-busy")
+
+	settings := domain.DefaultTeamSettings(teamName)
+	settings.MaxOpenReviews = 1
+	if _, err := service.UpdateTeamSettings(ctx, settings); err != nil {
+		t.Fatalf("UpdateTeamSettings failed: %v", err)
+	}
+
+	created, err := service.Create(ctx, domain.PullRequest{ID: "cap-pr-1", Title: "One", AuthorID: "cap-author"})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if ids := created.ReviewerIDs(); len(ids) != 1 || ids[0] != "cap-free" {
+		t.Fatalf("expected only cap-free below capacity, got %v", ids)
+	}
+
+	var svcErr *ServiceError
+	_, err = service.Create(ctx, domain.PullRequest{ID: "cap-pr-2", Title: "Two", AuthorID: "cap-author"})
+	if !errors.As(err, &svcErr) || svcErr.Code != ErrCodeCapacityExceeded {
+		t.Fatalf("expected ErrCodeCapacityExceeded, got %v", err)
+	}
+
+	limit := 2
+	if _, err = service.SetUserCapacity(ctx, "cap-busy", &limit); err != nil {
+		t.Fatalf("SetUserCapacity failed: %v", err)
+	}
+	created, err = service.Create(ctx, domain.PullRequest{ID: "cap-pr-3", Title: "Three", AuthorID: "cap-author"})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if ids := created.ReviewerIDs(); len(ids) != 1 || ids[0] != "cap-busy" {
+		t.Fatalf("expected personal limit to let cap-busy review, got %v", ids)
+	}
+
+	settings.QueueWhenFull = true
+	if _, err = service.UpdateTeamSettings(ctx, settings); err != nil {
+		t.Fatalf("UpdateTeamSettings failed: %v", err)
+	}
+	created, err = service.Create(ctx, domain.PullRequest{ID: "cap-pr-4", Title: "Queued", AuthorID: "cap-author"})
+	if err != nil {
+		t.Fatalf("Create with queue_when_full failed: %v", err)
+	}
+	if len(created.Reviewers) != 0 || !created.NeedsReviewers {
+		t.Fatalf("expected queued PR without reviewers, got %+v", created)
+	}
+}
+
+func TestPRService_FillPendingReviewers(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	teamStorage := postgres.NewTeamStorage(db)
+	userStorage := postgres.NewUserStorage(db)
+	prStorage := postgres.NewPullRequestStorage(db)
+	service := NewPRService(prStorage, userStorage, teamStorage, db)
+	ctx := context.Background()
+
+	teamName := "pending-team"
+	testutil.CleanupTeamData(t, db, teamName)
+
+	testutil.SeedTeam(t, teamStorage, userStorage, teamName, []domain.User{
+		{ID: "pd-author", Username: "Author", IsActive: true},
+		{ID: "pd-rev-1", Username: "Rev1", IsActive: true},
+	})
+
+	created, err := service.Create(ctx, domain.PullRequest{ID: "pd-pr", Title: "Understaffed", AuthorID: "pd-author"})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if len(created.Reviewers) != 1 || !created.NeedsReviewers {
+		t.Fatalf("expected one reviewer and needs_reviewers, got %+v", created)
+	}
+
+	waiting, err := service.GetUnassigned(ctx)
+	if err != nil {
+		t.Fatalf("GetUnassigned failed: %v", err)
+	}
+	found := false
+	for _, pr := range waiting {
+		found = found || pr.ID == "pd-pr"
+	}
+	if !found {
+		t.Fatalf("expected pd-pr in unassigned list")
+	}
+
+	if err = userStorage.Save(ctx, domain.User{ID: "pd-rev-2", Username: "Rev2", IsActive: true, TeamName: teamName}); err != nil {
+		t.Fatalf("failed to add member: %v", err)
+	}
+	if _, err = service.FillPendingReviewers(ctx); err != nil {
+		t.Fatalf("FillPendingReviewers failed: %v", err)
+	}
+
+	pr, err := service.GetPR(ctx, "pd-pr")
+	if err != nil {
+		t.Fatalf("GetPR failed: %v", err)
+	}
+	if len(pr.Reviewers) != 2 || pr.NeedsReviewers {
+		t.Fatalf("expected PR filled to two reviewers, got %+v", pr)
+	}
+}
+
+func TestPRService_ManualReviewers(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	teamStorage := postgres.NewTeamStorage(db)
+	userStorage := postgres.NewUserStorage(db)
+	prStorage := postgres.NewPullRequestStorage(db)
+	service := NewPRService(prStorage, userStorage, teamStorage, db)
+	ctx := context.Background()
+
+	teamName := "manual-team"
+	testutil.CleanupTeamData(t, db, teamName)
+
+	testutil.SeedTeam(t, teamStorage, userStorage, teamName, []domain.User{
+		{ID: "mn-author", Username: "Author", IsActive: true},
+		{ID: "mn-rev-1", Username: "Rev1", IsActive: true},
+		{ID: "mn-rev-2", Username: "Rev2", IsActive: true},
+		{ID: "mn-rev-3", Username: "Rev3", IsActive: true},
+		{ID: "mn-inactive", Username: "Gone", IsActive: false},
+	})
+	testutil.SeedPR(t, prStorage, db, domain.PullRequest{ID: "mn-pr", Title: "Manual", AuthorID: "mn-author"}, "mn-rev-1")
+
+	pr, err := service.AddReviewer(ctx, "mn-pr", "mn-rev-2")
+	if err != nil {
+		t.Fatalf("AddReviewer failed: %v", err)
+	}
+	if len(pr.Reviewers) != 2 {
+		t.Fatalf("expected two reviewers, got %v", pr.ReviewerIDs())
+	}
+
+	for _, tc := range []struct {
+		userID string
+		code   string
+	}{
+		{"mn-rev-2", ErrCodeAlreadyAssigned},
+		{"mn-author", ErrCodeInvalidReviewer},
+		{"mn-inactive", ErrCodeInvalidReviewer},
+		{"mn-missing", ErrCodeNotFound},
+	} {
+		_, err = service.AddReviewer(ctx, "mn-pr", tc.userID)
+		var svcErr *ServiceError
+		if !errors.As(err, &svcErr) || svcErr.Code != tc.code {
+			t.Fatalf("AddReviewer(%s): expected %s, got %v", tc.userID, tc.code, err)
+		}
+	}
+
+	newID, err := service.Reassign(ctx, "mn-pr", "mn-rev-1", "mn-rev-3")
+	if err != nil {
+		t.Fatalf("targeted Reassign failed: %v", err)
+	}
+	if newID != "mn-rev-3" {
+		t.Fatalf("expected mn-rev-3 to take over, got %s", newID)
+	}
+
+	pr, err = service.RemoveReviewer(ctx, "mn-pr", "mn-rev-2")
+	if err != nil {
+		t.Fatalf("RemoveReviewer failed: %v", err)
+	}
+	if ids := pr.ReviewerIDs(); len(ids) != 1 || ids[0] != "mn-rev-3" {
+		t.Fatalf("expected only mn-rev-3 left, got %v", ids)
+	}
+
+	if _, err = service.Merge(ctx, "mn-pr", true); err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	_, err = service.AddReviewer(ctx, "mn-pr", "mn-rev-1")
+	var svcErr *ServiceError
+	if !errors.As(err, &svcErr) || svcErr.Code != ErrCodePRMerged {
+		t.Fatalf("expected PR_MERGED on merged PR, got %v", err)
+	}
+}
+
+func TestPRService_Decline(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	teamStorage := postgres.NewTeamStorage(db)
+	userStorage := postgres.NewUserStorage(db)
+	prStorage := postgres.NewPullRequestStorage(db)
+	service := NewPRService(prStorage, userStorage, teamStorage, db)
+	ctx := context.Background()
+
+	teamName := "decline-team"
+	testutil.CleanupTeamData(t, db, teamName)
+
+	testutil.SeedTeam(t, teamStorage, userStorage, teamName, []domain.User{
+		{ID: "dc-author", Username: "Author", IsActive: true},
+		{ID: "dc-rev-1", Username: "Rev1", IsActive: true},
+		{ID: "dc-rev-2", Username: "Rev2", IsActive: true},
+		{ID: "dc-rev-3", Username: "Rev3", IsActive: true},
+	})
+	testutil.SeedPR(t, prStorage, db, domain.PullRequest{ID: "dc-pr", Title: "Decline", AuthorID: "dc-author"}, "dc-rev-1", "dc-rev-2")
+
+	newID, err := service.Decline(ctx, "dc-pr", "dc-rev-1", domain.DeclineReasonOverloaded)
+	if err != nil {
+		t.Fatalf("Decline failed: %v", err)
+	}
+	if newID != "dc-rev-3" {
+		t.Fatalf("expected dc-rev-3 as replacement, got %q", newID)
+	}
+
+	// the only other member declined already, so the second decline leaves the slot empty
+	newID, err = service.Decline(ctx, "dc-pr", "dc-rev-3", domain.DeclineReasonNoContext)
+	if err != nil {
+		t.Fatalf("second Decline failed: %v", err)
+	}
+	if newID != "" {
+		t.Fatalf("expected no replacement, got %q", newID)
+	}
+
+	if _, err = service.FillPendingReviewers(ctx); err != nil {
+		t.Fatalf("FillPendingReviewers failed: %v", err)
+	}
+	pr, err := service.GetPR(ctx, "dc-pr")
+	if err != nil {
+		t.Fatalf("GetPR failed: %v", err)
+	}
+	if ids := pr.ReviewerIDs(); len(ids) != 1 || ids[0] != "dc-rev-2" || !pr.NeedsReviewers {
+		t.Fatalf("expected decliners to stay off the PR, got %v (needs_reviewers=%v)", ids, pr.NeedsReviewers)
+	}
+
+	declines, err := service.GetDeclines(ctx, "dc-pr")
+	if err != nil {
+		t.Fatalf("GetDeclines failed: %v", err)
+	}
+	if len(declines) != 2 || declines[0].Reason != domain.DeclineReasonOverloaded {
+		t.Fatalf("unexpected declines: %+v", declines)
+	}
+
+	_, err = service.Decline(ctx, "dc-pr", "dc-rev-1", domain.DeclineReasonOverloaded)
+	var svcErr *ServiceError
+	if !errors.As(err, &svcErr) || svcErr.Code != ErrCodeNotAssigned {
+		t.Fatalf("expected NOT_ASSIGNED for a reviewer no longer assigned, got %v", err)
+	}
+}
+
+func TestPRService_Create_RequestedReviewers(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	teamStorage := postgres.NewTeamStorage(db)
+	userStorage := postgres.NewUserStorage(db)
+	prStorage := postgres.NewPullRequestStorage(db)
+	service := NewPRService(prStorage, userStorage, teamStorage, db)
+	ctx := context.Background()
+
+	teamName := "requested-team"
+	expertTeam := "requested-experts"
+	testutil.CleanupTeamData(t, db, teamName)
+	testutil.CleanupTeamData(t, db, expertTeam)
+
+	testutil.SeedTeam(t, teamStorage, userStorage, teamName, []domain.User{
+		{ID: "rq-author", Username: "Author", IsActive: true},
+		{ID: "rq-rev-1", Username: "Rev1", IsActive: true},
+		{ID: "rq-rev-2", Username: "Rev2", IsActive: true},
+		{ID: "rq-inactive", Username: "Gone", IsActive: false},
+	})
+	testutil.SeedTeam(t, teamStorage, userStorage, expertTeam, []domain.User{
+		{ID: "rq-expert", Username: "Expert", IsActive: true},
+	})
+
+	created, err := service.Create(ctx, domain.PullRequest{
+		ID:                 "rq-pr",
+		Title:              "Requested",
+		AuthorID:           "rq-author",
+		RequestedReviewers: []string{"rq-expert", "rq-inactive", "rq-author", "rq-expert", "rq-rev-1"},
+		ExcludedReviewers:  []string{"rq-rev-1"},
+	})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	ids := created.ReviewerIDs()
+	if len(ids) != 2 || ids[0] != "rq-expert" || ids[1] != "rq-rev-2" {
+		t.Fatalf("expected the expert plus rq-rev-2, got %v", ids)
+	}
+	if created.Reviewers[0].FallbackTeam != expertTeam {
+		t.Fatalf("expected expert to be recorded with fallback team, got %+v", created.Reviewers[0])
+	}
+
+	want := []domain.RejectedReviewer{
+		{UserID: "rq-inactive", Reason: domain.RejectReasonInactive},
+		{UserID: "rq-author", Reason: domain.RejectReasonAuthor},
+		{UserID: "rq-expert", Reason: domain.RejectReasonDuplicate},
+		{UserID: "rq-rev-1", Reason: domain.RejectReasonExcluded},
+	}
+	if len(created.RejectedReviewers) != len(want) {
+		t.Fatalf("expected %d rejections, got %+v", len(want), created.RejectedReviewers)
+	}
+	for i, r := range want {
+		if created.RejectedReviewers[i] != r {
+			t.Fatalf("rejection %d: want %+v, got %+v", i, r, created.RejectedReviewers[i])
+		}
+	}
+
+	// the exclusion is stored and applies to later picks too
+	if _, err = service.Reassign(ctx, "rq-pr", "rq-rev-2", ""); err == nil {
+		t.Fatalf("expected no replacement since rq-rev-1 is excluded")
+	}
+}
+
+func TestPRService_ExclusionRules(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	teamStorage := postgres.NewTeamStorage(db)
+	userStorage := postgres.NewUserStorage(db)
+	prStorage := postgres.NewPullRequestStorage(db)
+	service := NewPRService(prStorage, userStorage, teamStorage, db)
+	ctx := context.Background()
+
+	teamName := "exclusion-team"
+	testutil.CleanupTeamData(t, db, teamName)
+
+	testutil.SeedTeam(t, teamStorage, userStorage, teamName, []domain.User{
+		{ID: "ex-author", Username: "Report", IsActive: true},
+		{ID: "ex-manager", Username: "Manager", IsActive: true},
+		{ID: "ex-peer", Username: "Peer", IsActive: true},
+	})
+
+	_, err := service.AddExclusionRule(ctx, domain.ExclusionRule{ReviewerID: "ex-peer", AuthorID: "ex-peer"})
+	var svcErr *ServiceError
+	if !errors.As(err, &svcErr) || svcErr.Code != ErrCodeInvalidRule {
+		t.Fatalf("expected INVALID_RULE for a self rule, got %v", err)
+	}
+
+	rule, err := service.AddExclusionRule(ctx, domain.ExclusionRule{
+		ReviewerID: "ex-manager",
+		AuthorID:   "ex-author",
+		Reason:     "direct report",
+	})
+	if err != nil {
+		t.Fatalf("AddExclusionRule failed: %v", err)
+	}
+
+	created, err := service.Create(ctx, domain.PullRequest{
+		ID:                 "ex-pr",
+		Title:              "Excluded",
+		AuthorID:           "ex-author",
+		RequestedReviewers: []string{"ex-manager"},
+	})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if ids := created.ReviewerIDs(); len(ids) != 1 || ids[0] != "ex-peer" {
+		t.Fatalf("expected only ex-peer to be assigned, got %v", ids)
+	}
+	if len(created.RejectedReviewers) != 1 || created.RejectedReviewers[0].Reason != domain.RejectReasonConflict {
+		t.Fatalf("expected the manager request to be rejected as CONFLICT, got %+v", created.RejectedReviewers)
+	}
+
+	_, err = service.AddReviewer(ctx, "ex-pr", "ex-manager")
+	if !errors.As(err, &svcErr) || svcErr.Code != ErrCodeInvalidReviewer {
+		t.Fatalf("expected INVALID_REVIEWER for a manual add, got %v", err)
+	}
+	if _, err = service.Reassign(ctx, "ex-pr", "ex-peer", ""); err == nil {
+		t.Fatalf("expected Reassign to find no candidate besides the excluded manager")
+	}
+
+	if err = service.DeleteExclusionRule(ctx, rule.ID); err != nil {
+		t.Fatalf("DeleteExclusionRule failed: %v", err)
+	}
+	if _, err = service.AddReviewer(ctx, "ex-pr", "ex-manager"); err != nil {
+		t.Fatalf("AddReviewer after deleting the rule failed: %v", err)
+	}
+}
+
+func TestPRService_PairingMatrix(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	teamStorage := postgres.NewTeamStorage(db)
+	userStorage := postgres.NewUserStorage(db)
+	prStorage := postgres.NewPullRequestStorage(db)
+	service := NewPRService(prStorage, userStorage, teamStorage, db)
+	ctx := context.Background()
+
+	teamName := "pairing-team"
+	testutil.CleanupTeamData(t, db, teamName)
+
+	testutil.SeedTeam(t, teamStorage, userStorage, teamName, []domain.User{
+		{ID: "pa-author", Username: "Author", IsActive: true},
+		{ID: "pa-rev-1", Username: "Rev1", IsActive: true},
+		{ID: "pa-rev-2", Username: "Rev2", IsActive: true},
+		{ID: "pa-rev-3", Username: "Rev3", IsActive: true},
+		{ID: "pa-shadow", Username: "Shadow", IsActive: true},
+	})
+	testutil.SeedPR(t, prStorage, db, domain.PullRequest{ID: "pa-pr-1", Title: "One", AuthorID: "pa-author"}, "pa-rev-1")
+	testutil.SeedPR(t, prStorage, db, domain.PullRequest{ID: "pa-pr-2", Title: "Two", AuthorID: "pa-author"}, "pa-rev-1", "pa-rev-2")
+
+	// pa-rev-3 left both pull requests but still counts as paired with the author; the removed shadow does not
+	for _, event := range []domain.PREvent{
+		{PRID: "pa-pr-1", Type: domain.PREventRemoved, ReviewerID: "pa-rev-3"},
+		{PRID: "pa-pr-1", Type: domain.PREventRemoved, ReviewerID: "pa-shadow", Shadow: true},
+		{PRID: "pa-pr-2", Type: domain.PREventReassigned, ReviewerID: "pa-rev-2", PreviousReviewerID: "pa-rev-3"},
+	} {
+		if _, err := prStorage.SaveEvent(ctx, db, event); err != nil {
+			t.Fatalf("SaveEvent failed: %v", err)
+		}
+	}
+
+	settings := domain.DefaultTeamSettings(teamName)
+	settings.PairingWindowDays = 14
+	if _, err := service.UpdateTeamSettings(ctx, settings); err != nil {
+		t.Fatalf("UpdateTeamSettings failed: %v", err)
+	}
+
+	matrix, err := service.GetPairingMatrix(ctx, teamName, 0)
+	if err != nil {
+		t.Fatalf("GetPairingMatrix failed: %v", err)
+	}
+	if got := matrix.Pairs["pa-author"]; got["pa-rev-1"] != 2 || got["pa-rev-2"] != 1 || got["pa-rev-3"] != 2 {
+		t.Fatalf("unexpected pairings for pa-author: %v", got)
+	}
+	if _, ok := matrix.Pairs["pa-author"]["pa-shadow"]; ok {
+		t.Fatalf("expected the shadow reviewer to be left out, got %v", matrix.Pairs["pa-author"])
+	}
+	if age := time.Since(matrix.Since); age < 13*24*time.Hour || age > 15*24*time.Hour {
+		t.Fatalf("expected the team's 14 day window, got since %v", matrix.Since)
+	}
+}
+
+func TestPRService_SeniorAndShadowReviewers(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	teamStorage := postgres.NewTeamStorage(db)
+	userStorage := postgres.NewUserStorage(db)
+	prStorage := postgres.NewPullRequestStorage(db)
+	service := NewPRService(prStorage, userStorage, teamStorage, db)
+	ctx := context.Background()
+
+	teamName := "senior-team"
+	testutil.CleanupTeamData(t, db, teamName)
+
+	testutil.SeedTeam(t, teamStorage, userStorage, teamName, []domain.User{
+		{ID: "sn-author", Username: "Author", IsActive: true},
+		{ID: "sn-middle-1", Username: "Middle1", IsActive: true, Role: domain.RoleMiddle},
+		{ID: "sn-middle-2", Username: "Middle2", IsActive: true, Role: domain.RoleMiddle},
+		{ID: "sn-senior", Username: "Senior", IsActive: true, Role: domain.RoleSenior},
+		{ID: "sn-junior", Username: "Junior", IsActive: true, Role: domain.RoleJunior},
+	})
+
+	settings := domain.DefaultTeamSettings(teamName)
+	settings.RequireSenior = true
+	settings.ShadowReviewer = true
+	settings.RequiredApprovals = 2
+	settings.BlockOnChangesRequested = true
+	if _, err := service.UpdateTeamSettings(ctx, settings); err != nil {
+		t.Fatalf("UpdateTeamSettings failed: %v", err)
+	}
+
+	created, err := service.Create(ctx, domain.PullRequest{
+		ID:                 "sn-pr",
+		Title:              "Mentored",
+		AuthorID:           "sn-author",
+		RequestedReviewers: []string{"sn-middle-1", "sn-middle-2"},
+	})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if len(created.RejectedReviewers) != 1 || created.RejectedReviewers[0].Reason != domain.RejectReasonSeniorRequired {
+		t.Fatalf("expected the second middle to be rejected as SENIOR_REQUIRED, got %+v", created.RejectedReviewers)
+	}
+	if created.RequiredReviewerCount() != 2 || created.NeedsReviewers {
+		t.Fatalf("expected two required reviewers, got %+v", created.Reviewers)
+	}
+	if !isAssigned(*created, "sn-senior") || !created.IsShadowReviewer("sn-junior") {
+		t.Fatalf("expected sn-senior and a shadow sn-junior, got %+v", created.Reviewers)
+	}
+
+	_, err = service.Reassign(ctx, "sn-pr", "sn-junior", "")
+	var svcErr *ServiceError
+	if !errors.As(err, &svcErr) || svcErr.Code != ErrCodeInvalidReviewer {
+		t.Fatalf("expected INVALID_REVIEWER when reassigning a shadow, got %v", err)
+	}
+	_, err = service.Reassign(ctx, "sn-pr", "sn-senior", "sn-middle-2")
+	if !errors.As(err, &svcErr) || svcErr.Code != ErrCodeInvalidReviewer {
+		t.Fatalf("expected INVALID_REVIEWER when replacing the only senior, got %v", err)
+	}
+
+	for _, id := range []string{"sn-middle-1", "sn-senior"} {
+		if _, err = service.SubmitReview(ctx, "sn-pr", id, domain.ReviewStateApproved); err != nil {
+			t.Fatalf("SubmitReview by %s failed: %v", id, err)
+		}
+	}
+	if _, err = service.SubmitReview(ctx, "sn-pr", "sn-junior", domain.ReviewStateChangesRequested); err != nil {
+		t.Fatalf("SubmitReview by the shadow failed: %v", err)
+	}
+	if _, err = service.Merge(ctx, "sn-pr", false); err != nil {
+		t.Fatalf("expected the shadow reviewer not to block the merge, got %v", err)
+	}
+
+	// the only senior is the author, so the slots are filled without one until another senior joins
+	own, err := service.Create(ctx, domain.PullRequest{ID: "sn-pr-2", Title: "By the senior", AuthorID: "sn-senior"})
+	if err != nil {
+		t.Fatalf("Create by the senior failed: %v", err)
+	}
+	if own.RequiredReviewerCount() != 2 || !own.NeedsReviewers {
+		t.Fatalf("expected two reviewers and a pending senior, got %+v needs %v", own.Reviewers, own.NeedsReviewers)
+	}
+	if err = userStorage.Save(ctx, domain.User{ID: "sn-senior-2", Username: "Senior2", IsActive: true, TeamName: teamName, Role: domain.RoleSenior}); err != nil {
+		t.Fatalf("failed to save second senior: %v", err)
+	}
+	if _, err = service.FillPendingReviewers(ctx); err != nil {
+		t.Fatalf("FillPendingReviewers failed: %v", err)
+	}
+	own, err = service.GetPR(ctx, "sn-pr-2")
+	if err != nil {
+		t.Fatalf("GetPR failed: %v", err)
+	}
+	if !isAssigned(*own, "sn-senior-2") || own.RequiredReviewerCount() != 3 || own.NeedsReviewers {
+		t.Fatalf("expected the new senior to be added on top, got %+v needs %v", own.Reviewers, own.NeedsReviewers)
+	}
+}
+
+func TestPRService_SkillMatching(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	teamStorage := postgres.NewTeamStorage(db)
+	userStorage := postgres.NewUserStorage(db)
+	prStorage := postgres.NewPullRequestStorage(db)
+	service := NewPRService(prStorage, userStorage, teamStorage, db)
+	ctx := context.Background()
+
+	teamName := "skills-team"
+	testutil.CleanupTeamData(t, db, teamName)
+
+	testutil.SeedTeam(t, teamStorage, userStorage, teamName, []domain.User{
+		{ID: "sk-author", Username: "Author", IsActive: true},
+		{ID: "sk-dba", Username: "DBA", IsActive: true, Skills: []string{"sql"}},
+		{ID: "sk-ops", Username: "Ops", IsActive: true},
+		{ID: "sk-web", Username: "Web", IsActive: true, Skills: []string{"frontend"}},
+	})
+
+	settings := domain.DefaultTeamSettings(teamName)
+	settings.ReviewerCount = 1
+	if _, err := service.UpdateTeamSettings(ctx, settings); err != nil {
+		t.Fatalf("UpdateTeamSettings failed: %v", err)
+	}
+
+	created, err := service.Create(ctx, domain.PullRequest{ID: "sk-pr-1", Title: "Schema", AuthorID: "sk-author", Labels: []string{" SQL "}})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if ids := created.ReviewerIDs(); len(ids) != 1 || ids[0] != "sk-dba" {
+		t.Fatalf("expected the sql reviewer, got %v", ids)
+	}
+	if len(created.Labels) != 1 || created.Labels[0] != "sql" {
+		t.Fatalf("expected normalized labels, got %v", created.Labels)
+	}
+
+	skills := []string{"K8s", "k8s"}
+	updated, err := service.UpdateUser(ctx, "sk-ops", domain.UserUpdate{Skills: &skills})
+	if err != nil {
+		t.Fatalf("UpdateUser failed: %v", err)
+	}
+	if len(updated.Skills) != 1 || updated.Skills[0] != "k8s" || updated.Username != "Ops" {
+		t.Fatalf("unexpected updated user: %+v", updated)
+	}
+
+	created, err = service.Create(ctx, domain.PullRequest{ID: "sk-pr-2", Title: "Deploy", AuthorID: "sk-author", Labels: []string{"k8s"}})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if ids := created.ReviewerIDs(); len(ids) != 1 || ids[0] != "sk-ops" {
+		t.Fatalf("expected the k8s reviewer, got %v", ids)
+	}
+
+	created, err = service.Create(ctx, domain.PullRequest{ID: "sk-pr-3", Title: "Docs", AuthorID: "sk-author", Labels: []string{"docs"}})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if len(created.ReviewerIDs()) != 1 {
+		t.Fatalf("expected a reviewer from the normal pool, got %v", created.ReviewerIDs())
+	}
+}
+
+func TestPRService_SizeAwareReviewerCount(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	teamStorage := postgres.NewTeamStorage(db)
+	userStorage := postgres.NewUserStorage(db)
+	prStorage := postgres.NewPullRequestStorage(db)
+	service := NewPRService(prStorage, userStorage, teamStorage, db)
+	ctx := context.Background()
+
+	teamName := "size-team"
+	testutil.CleanupTeamData(t, db, teamName)
+
+	testutil.SeedTeam(t, teamStorage, userStorage, teamName, []domain.User{
+		{ID: "sz-author", Username: "Author", IsActive: true},
+		{ID: "sz-rev-1", Username: "Rev1", IsActive: true},
+		{ID: "sz-rev-2", Username: "Rev2", IsActive: true},
+		{ID: "sz-rev-3", Username: "Rev3", IsActive: true},
+	})
+
+	settings := domain.DefaultTeamSettings(teamName)
+	settings.ReviewerCountBySize = map[domain.PRSize]int{"XXL": 4}
+	_, err := service.UpdateTeamSettings(ctx, settings)
+	var svcErr *ServiceError
+	if !errors.As(err, &svcErr) || svcErr.Code != ErrCodeInvalidSettings {
+		t.Fatalf("expected INVALID_SETTINGS for an unknown size, got %v", err)
+	}
+
+	settings.ReviewerCountBySize = map[domain.PRSize]int{domain.PRSizeXS: 1, domain.PRSizeXL: 3}
+	if _, err = service.UpdateTeamSettings(ctx, settings); err != nil {
+		t.Fatalf("UpdateTeamSettings failed: %v", err)
+	}
+
+	tests := []struct {
+		id    string
+		diff  *domain.DiffStats
+		size  domain.PRSize
+		count int
+	}{
+		{id: "sz-typo", diff: &domain.DiffStats{LinesAdded: 1, LinesRemoved: 1, FilesChanged: 1}, size: domain.PRSizeXS, count: 1},
+		{id: "sz-refactor", diff: &domain.DiffStats{LinesAdded: 900, LinesRemoved: 700, FilesChanged: 40}, size: domain.PRSizeXL, count: 3},
+		{id: "sz-feature", diff: &domain.DiffStats{LinesAdded: 120, LinesRemoved: 30, FilesChanged: 6}, size: domain.PRSizeM, count: 2},
+		{id: "sz-unknown", count: 2},
+	}
+	for _, tt := range tests {
+		created, err := service.Create(ctx, domain.PullRequest{ID: tt.id, Title: tt.id, AuthorID: "sz-author", DiffStats: tt.diff})
+		if err != nil {
+			t.Fatalf("Create %s failed: %v", tt.id, err)
+		}
+		if created.Size != tt.size || len(created.Reviewers) != tt.count {
+			t.Fatalf("%s: expected size %q with %d reviewers, got %q with %v", tt.id, tt.size, tt.count, created.Size, created.ReviewerIDs())
+		}
+	}
+
+	stored, err := service.GetPR(ctx, "sz-refactor")
+	if err != nil {
+		t.Fatalf("GetPR failed: %v", err)
+	}
+	if stored.DiffStats == nil || stored.DiffStats.FilesChanged != 40 || stored.Size != domain.PRSizeXL {
+		t.Fatalf("expected diff stats to be stored, got %+v size %q", stored.DiffStats, stored.Size)
+	}
+}
+
+func TestPRService_ReviewSLAEscalation(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	teamStorage := postgres.NewTeamStorage(db)
+	userStorage := postgres.NewUserStorage(db)
+	prStorage := postgres.NewPullRequestStorage(db)
+	service := NewPRService(prStorage, userStorage, teamStorage, db)
+	ctx := context.Background()
+
+	teamName := "sla-team"
+	testutil.CleanupTeamData(t, db, teamName)
+
+	testutil.SeedTeam(t, teamStorage, userStorage, teamName, []domain.User{
+		{ID: "sla-author", Username: "Author", IsActive: true},
+		{ID: "sla-rev-1", Username: "Rev1", IsActive: true},
+		{ID: "sla-rev-2", Username: "Rev2", IsActive: true},
+		{ID: "sla-lead", Username: "Lead", IsActive: true, Role: domain.RoleLead},
+	})
+
+	settings := domain.DefaultTeamSettings(teamName)
+	settings.ReviewerCount = 1
+	settings.ReviewSLAHours = 24
+	settings.SLAEscalation = domain.EscalationAddLead
+	if _, err := service.UpdateTeamSettings(ctx, settings); err != nil {
+		t.Fatalf("UpdateTeamSettings failed: %v", err)
+	}
+
+	created, err := service.Create(ctx, domain.PullRequest{ID: "sla-pr", Title: "Slow", AuthorID: "sla-author", ExcludedReviewers: []string{"sla-lead"}})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	reviewer := created.Reviewers[0]
+	if reviewer.DueAt == nil || !reviewer.DueAt.After(time.Now()) {
+		t.Fatalf("expected a future due date, got %v", reviewer.DueAt)
+	}
+
+	// the lead was only excluded from the initial pick
+	if _, err = db.ExecContext(ctx, "UPDATE pull_requests SET excluded_reviewers = '{}' WHERE id = 'sla-pr'"); err != nil {
+		t.Fatalf("failed to clear exclusions: %v", err)
+	}
+	if _, err = db.ExecContext(ctx, "UPDATE pr_reviewers SET due_at = NOW() - INTERVAL '1 hour' WHERE pull_request_id = 'sla-pr'"); err != nil {
+		t.Fatalf("failed to move due date: %v", err)
+	}
+
+	overdue, err := service.GetOverdueReviews(ctx, teamName)
+	if err != nil {
+		t.Fatalf("GetOverdueReviews failed: %v", err)
+	}
+	if len(overdue) != 1 || overdue[0].ReviewerID != reviewer.UserID || overdue[0].EscalatedAt != nil {
+		t.Fatalf("expected the review of %s to be overdue, got %+v", reviewer.UserID, overdue)
+	}
+
+	escalations, err := service.EscalateOverdueReviews(ctx)
+	if err != nil {
+		t.Fatalf("EscalateOverdueReviews failed: %v", err)
+	}
+	var found *domain.ReviewEscalation
+	for i := range escalations {
+		if escalations[i].PRID == "sla-pr" {
+			found = &escalations[i]
+		}
+	}
+	if found == nil || found.Action != domain.EscalationAddLead || found.NewReviewerID != "sla-lead" {
+		t.Fatalf("expected the lead to be added, got %+v", escalations)
+	}
+
+	pr, err := service.GetPR(ctx, "sla-pr")
+	if err != nil {
+		t.Fatalf("GetPR failed: %v", err)
+	}
+	if !isAssigned(*pr, "sla-lead") || !isAssigned(*pr, reviewer.UserID) {
+		t.Fatalf("expected both reviewers to stay assigned, got %v", pr.ReviewerIDs())
+	}
+	history, err := service.GetPRHistory(ctx, "sla-pr")
+	if err != nil {
+		t.Fatalf("GetPRHistory failed: %v", err)
+	}
+	last := history[len(history)-1]
+	if last.Type != domain.PREventEscalated || last.ReviewerID != reviewer.UserID || last.Reason != string(domain.EscalationAddLead) {
+		t.Fatalf("expected the escalation to be recorded, got %+v", last)
+	}
+
+	escalations, err = service.EscalateOverdueReviews(ctx)
+	if err != nil {
+		t.Fatalf("second EscalateOverdueReviews failed: %v", err)
+	}
+	for _, e := range escalations {
+		if e.PRID == "sla-pr" {
+			t.Fatalf("expected the review to be escalated only once, got %+v", e)
+		}
+	}
+
+	// a worker holding the list from before the escalation must not escalate the review again
+	stale, err := service.escalateReview(ctx, overdue[0])
+	if err != nil || stale != nil {
+		t.Fatalf("expected stale overdue review to be skipped, got %+v %v", stale, err)
+	}
+}
+
+func TestPRService_WorkingHours(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	teamStorage := postgres.NewTeamStorage(db)
+	userStorage := postgres.NewUserStorage(db)
+	prStorage := postgres.NewPullRequestStorage(db)
+	service := NewPRService(prStorage, userStorage, teamStorage, db)
+	ctx := context.Background()
+
+	teamName := "wh-team"
+	testutil.CleanupTeamData(t, db, teamName)
+
+	// one reviewer works around the clock, the other is off for the current UTC hour
+	offStart, offEnd := 12, 24
+	if time.Now().UTC().Hour() >= 12 {
+		offStart, offEnd = 0, 12
+	}
+	testutil.SeedTeam(t, teamStorage, userStorage, teamName, []domain.User{
+		{ID: "wh-author", Username: "Author", IsActive: true},
+		{ID: "wh-always", Username: "Always", IsActive: true, WorkStartHour: 0, WorkEndHour: 24, WorkDays: []int{1, 2, 3, 4, 5, 6, 7}},
+		{ID: "wh-off", Username: "Off", IsActive: true, WorkStartHour: offStart, WorkEndHour: offEnd, WorkDays: []int{1, 2, 3, 4, 5, 6, 7}},
+	})
+
+	settings := domain.DefaultTeamSettings(teamName)
+	settings.ReviewerCount = 1
+	settings.MinReviewers = 1
+	settings.RequiredApprovals = 1
+	settings.ReviewSLAHours = 2
+	if _, err := service.UpdateTeamSettings(ctx, settings); err != nil {
+		t.Fatalf("UpdateTeamSettings failed: %v", err)
+	}
+
+	for _, id := range []string{"wh-pr-1", "wh-pr-2", "wh-pr-3"} {
+		created, err := service.Create(ctx, domain.PullRequest{ID: id, Title: "Hours", AuthorID: "wh-author"})
+		if err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		if len(created.Reviewers) != 1 || created.Reviewers[0].UserID != "wh-always" {
+			t.Fatalf("expected the reviewer in working hours, got %+v", created.Reviewers)
+		}
+		// the reviewer works around the clock, so the SLA counts plain hours
+		due := created.Reviewers[0].DueAt
+		if due == nil || due.Sub(time.Now()) < time.Hour || due.Sub(time.Now()) > 2*time.Hour+time.Minute {
+			t.Fatalf("expected a due date in about two hours, got %v", due)
+		}
+	}
+
+	// when nobody else is left the off-hours reviewer still gets the review
+	if _, _, err := service.SetUserActive(ctx, "wh-always", false); err != nil {
+		t.Fatalf("SetUserActive failed: %v", err)
+	}
+	created, err := service.Create(ctx, domain.PullRequest{ID: "wh-pr-off", Title: "Hours", AuthorID: "wh-author"})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if len(created.Reviewers) != 1 || created.Reviewers[0].UserID != "wh-off" {
+		t.Fatalf("expected the off-hours reviewer as a fallback, got %+v", created.Reviewers)
+	}
+
+	// holidays
+	if _, err = service.AddHoliday(ctx, domain.Holiday{TeamName: teamName, Date: "2025-12-31", Name: "New Year's Eve"}); err != nil {
+		t.Fatalf("AddHoliday failed: %v", err)
+	}
+	holidays, err := service.GetHolidays(ctx, teamName)
+	if err != nil {
+		t.Fatalf("GetHolidays failed: %v", err)
+	}
+	if len(holidays) != 1 || holidays[0].Date != "2025-12-31" || holidays[0].Name != "New Year's Eve" {
+		t.Fatalf("unexpected holidays %+v", holidays)
+	}
+	if err = service.DeleteHoliday(ctx, teamName, "2025-12-31"); err != nil {
+		t.Fatalf("DeleteHoliday failed: %v", err)
+	}
+	var svcErr *ServiceError
+	if err = service.DeleteHoliday(ctx, teamName, "2025-12-31"); !errors.As(err, &svcErr) || svcErr.Code != ErrCodeNotFound {
+		t.Fatalf("expected ErrCodeNotFound, got %v", err)
+	}
+
+	// schedule validation
+	badZone := "Mars/Olympus"
+	if _, err = service.UpdateUser(ctx, "wh-off", domain.UserUpdate{TimeZone: &badZone}); !errors.As(err, &svcErr) || svcErr.Code != ErrCodeInvalidSettings {
+		t.Fatalf("expected ErrCodeInvalidSettings, got %v", err)
+	}
+	zone := "Asia/Yerevan"
+	updated, err := service.UpdateUser(ctx, "wh-off", domain.UserUpdate{TimeZone: &zone})
+	if err != nil {
+		t.Fatalf("UpdateUser failed: %v", err)
+	}
+	if updated.TimeZone != zone || updated.WorkStartHour != offStart || len(updated.WorkDays) != 7 {
+		t.Fatalf("unexpected schedule %+v", updated)
+	}
+}
+
+func TestPRService_History(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	teamStorage := postgres.NewTeamStorage(db)
+	userStorage := postgres.NewUserStorage(db)
+	prStorage := postgres.NewPullRequestStorage(db)
+	service := NewPRService(prStorage, userStorage, teamStorage, db)
+	ctx := WithActor(context.Background(), "hist-bot")
+
+	teamName := "hist-team"
+	testutil.CleanupTeamData(t, db, teamName)
+
+	testutil.SeedTeam(t, teamStorage, userStorage, teamName, []domain.User{
+		{ID: "hist-author", Username: "Author", IsActive: true},
+		{ID: "hist-rev-1", Username: "Rev1", IsActive: true},
+		{ID: "hist-rev-2", Username: "Rev2", IsActive: true},
+		{ID: "hist-rev-3", Username: "Rev3", IsActive: true},
+	})
+
+	settings := domain.DefaultTeamSettings(teamName)
+	settings.ReviewerCount = 1
+	settings.MinReviewers = 1
+	settings.RequiredApprovals = 0
+	if _, err := service.UpdateTeamSettings(ctx, settings); err != nil {
+		t.Fatalf("UpdateTeamSettings failed: %v", err)
+	}
+
+	created, err := service.Create(ctx, domain.PullRequest{ID: "hist-pr", Title: "History", AuthorID: "hist-author"})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	original := created.Reviewers[0].UserID
+
+	replacement, err := service.Reassign(WithActor(context.Background(), "alice"), "hist-pr", original, "")
+	if err != nil {
+		t.Fatalf("Reassign failed: %v", err)
+	}
+	if _, err = service.Decline(ctx, "hist-pr", replacement, domain.DeclineReasonOverloaded); err != nil {
+		t.Fatalf("Decline failed: %v", err)
+	}
+	if _, err = service.Merge(ctx, "hist-pr", true); err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+
+	events, err := service.GetPRHistory(ctx, "hist-pr")
+	if err != nil {
+		t.Fatalf("GetPRHistory failed: %v", err)
+	}
+	wantTypes := []domain.PREventType{
+		domain.PREventAssigned, domain.PREventReassigned, domain.PREventDeclined, domain.PREventReassigned, domain.PREventMerged,
+	}
+	if len(events) != len(wantTypes) {
+		t.Fatalf("expected %d events, got %+v", len(wantTypes), events)
+	}
+	for i, want := range wantTypes {
+		if events[i].Type != want {
+			t.Fatalf("event %d: want %s, got %+v", i, want, events[i])
+		}
+	}
+
+	if events[0].ReviewerID != original || events[0].Strategy != string(domain.DefaultReviewerStrategy) || events[0].Actor != "hist-bot" {
+		t.Fatalf("unexpected assignment event %+v", events[0])
+	}
+	if events[1].PreviousReviewerID != original || events[1].ReviewerID != replacement || events[1].Actor != "alice" {
+		t.Fatalf("unexpected reassignment event %+v", events[1])
+	}
+	if events[2].ReviewerID != replacement || events[2].Reason != string(domain.DeclineReasonOverloaded) {
+		t.Fatalf("unexpected decline event %+v", events[2])
+	}
+	if events[3].PreviousReviewerID != replacement {
+		t.Fatalf("unexpected replacement after decline %+v", events[3])
+	}
+	if events[4].Reason == "" {
+		t.Fatalf("expected the forced merge to be explained, got %+v", events[4])
+	}
+
+	var svcErr *ServiceError
+	if _, err = service.GetPRHistory(ctx, "hist-missing"); !errors.As(err, &svcErr) || svcErr.Code != ErrCodeNotFound {
+		t.Fatalf("expected ErrCodeNotFound, got %v", err)
+	}
+}
+
+func TestPRService_Webhooks(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	teamStorage := postgres.NewTeamStorage(db)
+	userStorage := postgres.NewUserStorage(db)
+	prStorage := postgres.NewPullRequestStorage(db)
+	service := NewPRService(prStorage, userStorage, teamStorage, db)
+	service.SetWebhookStorage(postgres.NewWebhookStorage(db))
+	service.AllowPrivateWebhooks()
+	ctx := context.Background()
+
+	teamName := "hook-team"
+	testutil.CleanupTeamData(t, db, teamName)
+	if _, err := db.ExecContext(ctx, "DELETE FROM webhook_subscriptions"); err != nil {
+		t.Fatalf("failed to clean webhooks: %v", err)
+	}
+
+	testutil.SeedTeam(t, teamStorage, userStorage, teamName, []domain.User{
+		{ID: "hook-author", Username: "Author", IsActive: true},
+		{ID: "hook-rev", Username: "Rev", IsActive: true},
+	})
+	settings := domain.DefaultTeamSettings(teamName)
+	settings.ReviewerCount = 1
+	settings.MinReviewers = 1
+	settings.RequiredApprovals = 0
+	if _, err := service.UpdateTeamSettings(ctx, settings); err != nil {
+		t.Fatalf("UpdateTeamSettings failed: %v", err)
+	}
+
+	var (
+		received []string
+		failing  bool
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get(webhookSignatureHeader) != signPayload("hook-secret", body) {
+			t.Errorf("bad signature for %s", body)
+		}
+		if failing {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		// other packages may create pull requests while this test runs
+		if strings.Contains(string(body), `"hook-pr"`) {
+			received = append(received, r.Header.Get(webhookEventHeader))
+		}
+	}))
+	defer server.Close()
+
+	var svcErr *ServiceError
+	_, err := service.CreateWebhook(ctx, domain.Webhook{URL: "ftp://bot", EventTypes: []domain.WebhookEventType{domain.WebhookPRCreated}})
+	if !errors.As(err, &svcErr) || svcErr.Code != ErrCodeInvalidWebhook {
+		t.Fatalf("expected ErrCodeInvalidWebhook, got %v", err)
+	}
+	webhook, err := service.CreateWebhook(ctx, domain.Webhook{
+		URL:        server.URL,
+		Secret:     "hook-secret",
+		EventTypes: []domain.WebhookEventType{domain.WebhookPRCreated, domain.WebhookReviewerAssigned, domain.WebhookPRMerged},
+	})
+	if err != nil {
+		t.Fatalf("CreateWebhook failed: %v", err)
+	}
+
+	if _, err = service.Create(ctx, domain.PullRequest{ID: "hook-pr", Title: "Hooks", AuthorID: "hook-author"}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if _, err = service.DeliverWebhooks(ctx); err != nil {
+		t.Fatalf("DeliverWebhooks failed: %v", err)
+	}
+	if len(received) != 2 || received[0] != "pr.created" || received[1] != "reviewer.assigned" {
+		t.Fatalf("unexpected deliveries %v", received)
+	}
+
+	// a failed delivery is retried later
+	failing = true
+	if _, err = service.Merge(ctx, "hook-pr", false); err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if _, err = service.DeliverWebhooks(ctx); err != nil {
+		t.Fatalf("DeliverWebhooks failed: %v", err)
+	}
+	deliveries, err := service.GetWebhookDeliveries(ctx, webhook.ID)
+	if err != nil {
+		t.Fatalf("GetWebhookDeliveries failed: %v", err)
+	}
+	var merged domain.WebhookDelivery
+	for _, d := range deliveries {
+		if d.EventType == domain.WebhookPRMerged {
+			merged = d
+		}
+	}
+	if merged.EventType != domain.WebhookPRMerged || merged.Status != domain.DeliveryPending || merged.Attempts != 1 ||
+		merged.LastStatusCode != http.StatusServiceUnavailable || !merged.NextAttemptAt.After(time.Now()) {
+		t.Fatalf("expected a delivery waiting for retry, got %+v", merged)
+	}
+
+	// manual redelivery is sent right away
+	failing = false
+	redelivered, err := service.RedeliverWebhook(ctx, merged.ID)
+	if err != nil {
+		t.Fatalf("RedeliverWebhook failed: %v", err)
+	}
+	if _, err = service.DeliverWebhooks(ctx); err != nil {
+		t.Fatalf("DeliverWebhooks failed: %v", err)
+	}
+	if received[len(received)-1] != "pr.merged" {
+		t.Fatalf("expected pr.merged to be redelivered, got %v", received)
+	}
+	deliveries, err = service.GetWebhookDeliveries(ctx, webhook.ID)
+	if err != nil {
+		t.Fatalf("GetWebhookDeliveries failed: %v", err)
+	}
+	for _, d := range deliveries {
+		if d.ID == redelivered.ID && (d.Status != domain.DeliveryDelivered || d.DeliveredAt == nil) {
+			t.Fatalf("unexpected redelivery %+v", d)
+		}
+	}
+
+	if err = service.DeleteWebhook(ctx, webhook.ID); err != nil {
+		t.Fatalf("DeleteWebhook failed: %v", err)
+	}
+	if _, err = service.RedeliverWebhook(ctx, merged.ID); !errors.As(err, &svcErr) || svcErr.Code != ErrCodeNotFound {
+		t.Fatalf("expected ErrCodeNotFound, got %v", err)
+	}
+}
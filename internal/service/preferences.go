@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/neizhmak/avito-review-service/internal/domain"
+	"github.com/neizhmak/avito-review-service/internal/storage"
+)
+
+// requestedAssignments checks the reviewers requested by the author of a pull request, in order, and returns the
+// eligible ones as assignments together with the rejected ones. A requested reviewer must exist, be active and
+// available, not be the author, excluded by the author or by an exclusion rule, and have review capacity left;
+// at most the reviewer count for the pull request size are accepted. If the team requires a senior reviewer, the
+// last slot is kept for a senior.
+func (s *PRService) requestedAssignments(
+	ctx context.Context,
+	pr domain.PullRequest,
+	settings domain.TeamSettings,
+	authorTeam string,
+) ([]assignment, []domain.RejectedReviewer, error) {
+	excluded := make(map[string]bool, len(pr.ExcludedReviewers))
+	for _, id := range pr.ExcludedReviewers {
+		excluded[id] = true
+	}
+	blocked, err := s.blockedReviewers(ctx, pr.AuthorID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reviewerCount := settings.ReviewerCountFor(pr.Size)
+	var accepted []assignment
+	rejected := make([]domain.RejectedReviewer, 0)
+	seen := make(map[string]bool, len(pr.RequestedReviewers))
+	for _, id := range pr.RequestedReviewers {
+		reason, err := s.requestRejectReason(ctx, id, pr.AuthorID, excluded, blocked, seen)
+		if err != nil {
+			return nil, nil, err
+		}
+		if reason == "" && len(accepted) >= reviewerCount {
+			reason = domain.RejectReasonTooMany
+		}
+		seen[id] = true
+		if reason != "" {
+			rejected = append(rejected, domain.RejectedReviewer{UserID: id, Reason: reason})
+			continue
+		}
+
+		user, err := s.userStorage.GetByID(ctx, id)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get requested reviewer: %w", err)
+		}
+		if settings.RequireSenior && !user.Role.IsSenior() && len(accepted) == reviewerCount-1 &&
+			!hasSenior(assignedUsers(accepted)) {
+			rejected = append(rejected, domain.RejectedReviewer{UserID: id, Reason: domain.RejectReasonSeniorRequired})
+			continue
+		}
+		a := assignment{User: *user, Strategy: strategyRequested}
+		if user.TeamName != authorTeam {
+			a.FallbackTeam = user.TeamName
+		}
+		accepted = append(accepted, a)
+	}
+
+	return accepted, rejected, nil
+}
+
+// requestRejectReason returns why a requested reviewer cannot be assigned, or an empty reason if they can.
+func (s *PRService) requestRejectReason(
+	ctx context.Context,
+	userID, authorID string,
+	excluded, blocked, seen map[string]bool,
+) (domain.RejectReason, error) {
+	switch {
+	case seen[userID]:
+		return domain.RejectReasonDuplicate, nil
+	case userID == authorID:
+		return domain.RejectReasonAuthor, nil
+	case excluded[userID]:
+		return domain.RejectReasonExcluded, nil
+	case blocked[userID]:
+		return domain.RejectReasonConflict, nil
+	}
+
+	user, err := s.userStorage.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return domain.RejectReasonNotFound, nil
+		}
+		return "", fmt.Errorf("failed to get requested reviewer: %w", err)
+	}
+	if !user.IsActive {
+		return domain.RejectReasonInactive, nil
+	}
+
+	unavailable, err := s.userStorage.IsUnavailable(ctx, userID, time.Now())
+	if err != nil {
+		return "", err
+	}
+	if unavailable {
+		return domain.RejectReasonUnavailable, nil
+	}
+
+	available, _, err := s.withinCapacity(ctx, []domain.User{*user})
+	if err != nil {
+		return "", err
+	}
+	if len(available) == 0 {
+		return domain.RejectReasonAtCapacity, nil
+	}
+	return "", nil
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/neizhmak/avito-review-service/internal/domain"
+	"github.com/neizhmak/avito-review-service/internal/storage"
+)
+
+// SubmitReview records the review state of an assigned reviewer and returns the updated pull request.
+func (s *PRService) SubmitReview(ctx context.Context, prID, reviewerID string, state domain.ReviewState) (*domain.PullRequest, error) {
+	pr, err := s.prStorage.GetByID(ctx, prID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, notFound("pr not found")
+		}
+		return nil, fmt.Errorf("failed to get pr: %w", err)
+	}
+	if err = requireOpen(pr, "review"); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// a merge holding the lock evaluates its policy before this review is recorded
+	if pr.Status, err = s.prStorage.LockStatus(ctx, tx, prID); err != nil {
+		return nil, err
+	}
+	if err = requireOpen(pr, "review"); err != nil {
+		return nil, err
+	}
+	if err = s.prStorage.UpdateReviewState(ctx, tx, prID, reviewerID, state); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, conflict(ErrCodeNotAssigned, "reviewer is not assigned to this PR")
+		}
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit tx: %w", err)
+	}
+
+	return s.GetPR(ctx, prID)
+}
+
+// checkMergePolicy returns a MERGE_BLOCKED error if the reviews do not satisfy the team's merge policy.
+// Shadow reviewers never block a merge and their approvals do not count.
+func checkMergePolicy(settings domain.TeamSettings, reviewers []domain.AssignedReviewer) error {
+	approvals := 0
+	for _, r := range reviewers {
+		if r.Shadow {
+			continue
+		}
+		switch r.State {
+		case domain.ReviewStateApproved:
+			approvals++
+		case domain.ReviewStateChangesRequested:
+			if settings.BlockOnChangesRequested {
+				return conflict(ErrCodeMergeBlocked, "changes requested by "+r.UserID)
+			}
+		}
+	}
+
+	if approvals < settings.RequiredApprovals {
+		return conflict(ErrCodeMergeBlocked, "not enough approvals: "+strconv.Itoa(approvals)+" of "+strconv.Itoa(settings.RequiredApprovals))
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/neizhmak/avito-review-service/internal/domain"
+)
+
+func TestCheckMergePolicy(t *testing.T) {
+	reviews := func(states ...domain.ReviewState) []domain.AssignedReviewer {
+		result := make([]domain.AssignedReviewer, 0, len(states))
+		for i, state := range states {
+			result = append(result, domain.AssignedReviewer{UserID: string(rune('a' + i)), State: state})
+		}
+		return result
+	}
+
+	tests := []struct {
+		name      string
+		approvals int
+		block     bool
+		reviewers []domain.AssignedReviewer
+		wantBlock bool
+	}{
+		{name: "no policy", reviewers: reviews(domain.ReviewStatePending)},
+		{name: "enough approvals", approvals: 2, reviewers: reviews(domain.ReviewStateApproved, domain.ReviewStateApproved)},
+		{name: "missing approval", approvals: 2, reviewers: reviews(domain.ReviewStateApproved, domain.ReviewStatePending), wantBlock: true},
+		{name: "dismissed does not count", approvals: 1, reviewers: reviews(domain.ReviewStateDismissed), wantBlock: true},
+		{name: "changes requested blocks", approvals: 1, block: true, reviewers: reviews(domain.ReviewStateApproved, domain.ReviewStateChangesRequested), wantBlock: true},
+		{name: "changes requested ignored", approvals: 1, reviewers: reviews(domain.ReviewStateApproved, domain.ReviewStateChangesRequested)},
+		{
+			name:      "shadow does not block",
+			approvals: 1,
+			block:     true,
+			reviewers: []domain.AssignedReviewer{
+				{UserID: "a", State: domain.ReviewStateApproved},
+				{UserID: "b", State: domain.ReviewStateChangesRequested, Shadow: true},
+			},
+		},
+		{
+			name:      "shadow approval does not count",
+			approvals: 1,
+			reviewers: []domain.AssignedReviewer{{UserID: "a", State: domain.ReviewStateApproved, Shadow: true}},
+			wantBlock: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := domain.DefaultTeamSettings("team")
+			settings.RequiredApprovals = tt.approvals
+			settings.BlockOnChangesRequested = tt.block
+
+			err := checkMergePolicy(settings, tt.reviewers)
+			var svcErr *ServiceError
+			blocked := errors.As(err, &svcErr) && svcErr.Code == ErrCodeMergeBlocked
+			if blocked != tt.wantBlock || (err != nil && !blocked) {
+				t.Fatalf("want blocked=%v, got %v", tt.wantBlock, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/neizhmak/avito-review-service/internal/domain"
+	"github.com/neizhmak/avito-review-service/internal/storage"
+)
+
+// AddReviewer assigns a specific user as an additional reviewer of an open pull request.
+// Capacity limits and unavailability periods are not checked: the caller chose the reviewer explicitly.
+func (s *PRService) AddReviewer(ctx context.Context, prID, userID string) (*domain.PullRequest, error) {
+	pr, err := s.prStorage.GetByID(ctx, prID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, notFound("pr not found")
+		}
+		return nil, fmt.Errorf("failed to get pr: %w", err)
+	}
+	if err = requireOpen(pr, "add reviewer to"); err != nil {
+		return nil, err
+	}
+
+	author, err := s.userStorage.GetByID(ctx, pr.AuthorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get author: %w", err)
+	}
+	picked, err := s.manualAssignment(ctx, *pr, *author, userID)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := s.teamStorage.GetSettings(ctx, author.TeamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team settings: %w", err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// the pending reviewer worker may be filling the pull request at the same time
+	if _, err = s.prStorage.LockNeedingReviewers(ctx, tx, prID); err != nil {
+		return nil, err
+	}
+	if pr, err = s.prStorage.GetByID(ctx, prID); err != nil {
+		return nil, fmt.Errorf("failed to get pr: %w", err)
+	}
+	if isAssigned(*pr, userID) {
+		return nil, conflict(ErrCodeAlreadyAssigned, "user "+userID+" is already assigned to this PR")
+	}
+
+	if err = s.saveAssignments(ctx, tx, *pr, []assignment{picked}, "added manually"); err != nil {
+		return nil, err
+	}
+	if pr.NeedsReviewers && pr.RequiredReviewerCount()+1 >= settings.ReviewerCountFor(pr.Size) {
+		assigned, err := s.requiredReviewers(ctx, *pr)
+		if err != nil {
+			return nil, err
+		}
+		if !lacksSenior(*settings, append(assigned, picked.User)) {
+			if err = s.prStorage.SetNeedsReviewers(ctx, tx, prID, false); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit tx: %w", err)
+	}
+
+	return s.GetPR(ctx, prID)
+}
+
+// RemoveReviewer unassigns a reviewer from an open pull request without picking a replacement.
+// The pull request is not queued for the pending reviewer worker, so the removal sticks.
+func (s *PRService) RemoveReviewer(ctx context.Context, prID, userID string) (*domain.PullRequest, error) {
+	pr, _, err := s.assignedReviewer(ctx, prID, userID, "remove reviewer from")
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err = s.prStorage.DeleteReviewer(ctx, tx, prID, userID); err != nil {
+		return nil, err
+	}
+	if err = s.recordRemoval(ctx, tx, prID, userID, "removed manually", pr.IsShadowReviewer(userID)); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit tx: %w", err)
+	}
+
+	return s.GetPR(ctx, prID)
+}
+
+// manualAssignment validates a reviewer named explicitly for a pull request: the user must exist, be active,
+// not be the author, not be barred by an exclusion rule and not be assigned already. Users outside the author's
+// team are recorded with their team as the fallback team.
+func (s *PRService) manualAssignment(ctx context.Context, pr domain.PullRequest, author domain.User, userID string) (assignment, error) {
+	user, err := s.userStorage.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return assignment{}, notFound("user not found")
+		}
+		return assignment{}, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if !user.IsActive {
+		return assignment{}, conflict(ErrCodeInvalidReviewer, "user "+user.ID+" is not active")
+	}
+	if user.ID == pr.AuthorID {
+		return assignment{}, conflict(ErrCodeInvalidReviewer, "author cannot review own PR")
+	}
+	blocked, err := s.blockedReviewers(ctx, pr.AuthorID)
+	if err != nil {
+		return assignment{}, err
+	}
+	if blocked[user.ID] {
+		return assignment{}, conflict(ErrCodeInvalidReviewer, "an exclusion rule forbids "+user.ID+" from reviewing this author")
+	}
+	if isAssigned(pr, user.ID) {
+		return assignment{}, conflict(ErrCodeAlreadyAssigned, "user "+user.ID+" is already assigned to this PR")
+	}
+
+	picked := assignment{User: *user, Strategy: strategyManual}
+	if user.TeamName != author.TeamName {
+		picked.FallbackTeam = user.TeamName
+	}
+	return picked, nil
+}
+
+// isAssigned reports whether userID is among the reviewers of the pull request.
+func isAssigned(pr domain.PullRequest, userID string) bool {
+	for _, id := range pr.ReviewerIDs() {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
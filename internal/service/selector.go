@@ -0,0 +1,151 @@
+package service
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/neizhmak/avito-review-service/internal/domain"
+)
+
+// ReviewerPool describes the candidates available for a single assignment.
+// Candidates are already filtered: the author, current reviewers and inactive users are excluded.
+type ReviewerPool struct {
+	TeamName   string
+	Candidates []domain.User
+	// OpenReviews holds the number of OPEN pull requests each candidate is currently reviewing.
+	OpenReviews map[string]int
+	// Load holds the open reviews of each candidate weighted by pull request size (see domain.PRSize.Weight).
+	Load map[string]int
+	// RecentPairings holds how many of the author's pull requests each candidate was assigned to within the team's
+	// pairing window. It is empty when the window is disabled.
+	RecentPairings map[string]int
+	// LastPicked is the last reviewer picked by the team's round robin rotation, empty if it has not started.
+	LastPicked string
+}
+
+// ReviewerSelector picks up to count reviewers from a pool.
+type ReviewerSelector interface {
+	Select(pool ReviewerPool, count int) []domain.User
+}
+
+// defaultSelectors returns the built-in strategies keyed by name.
+func defaultSelectors() map[domain.ReviewerStrategy]ReviewerSelector {
+	return map[domain.ReviewerStrategy]ReviewerSelector{
+		domain.StrategyRandom:      RandomSelector{},
+		domain.StrategyRoundRobin:  RoundRobinSelector{},
+		domain.StrategyLeastLoaded: LeastLoadedSelector{},
+	}
+}
+
+// RandomSelector picks reviewers at random, uniformly unless recent pairings with the author make some less likely.
+type RandomSelector struct{}
+
+// Select shuffles the candidates and takes the first count of them.
+func (RandomSelector) Select(pool ReviewerPool, count int) []domain.User {
+	return firstN(weightedShuffle(pool.Candidates, pool.RecentPairings), count)
+}
+
+// RoundRobinSelector rotates through team members in user id order, continuing after pool.LastPicked.
+// The service keeps the rotation cursor of each team in its settings and advances it with advanceRotation.
+type RoundRobinSelector struct{}
+
+// Select returns the candidates following the last one picked for the team.
+func (RoundRobinSelector) Select(pool ReviewerPool, count int) []domain.User {
+	if len(pool.Candidates) == 0 || count <= 0 {
+		return nil
+	}
+
+	ordered := append([]domain.User(nil), pool.Candidates...)
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].ID < ordered[j].ID })
+
+	// start right after the last picked id; ids that left the pool are skipped naturally
+	start := sort.Search(len(ordered), func(i int) bool { return ordered[i].ID > pool.LastPicked })
+
+	if count > len(ordered) {
+		count = len(ordered)
+	}
+	picked := make([]domain.User, 0, count)
+	for i := 0; i < count; i++ {
+		picked = append(picked, ordered[(start+i)%len(ordered)])
+	}
+	return picked
+}
+
+// advanceRotation returns the rotation cursor after picking users from a rotation that continued after last:
+// the picked user furthest along the rotation in user id order.
+func advanceRotation(last string, picked []domain.User) string {
+	next := last
+	wrapped := false
+	for _, u := range picked {
+		switch {
+		case u.ID > last:
+			if !wrapped && u.ID > next {
+				next = u.ID
+			}
+		case !wrapped || u.ID > next:
+			// users at or before last are only reached after the rotation wrapped around
+			next = u.ID
+			wrapped = true
+		}
+	}
+	return next
+}
+
+// LeastLoadedSelector prefers candidates with the lowest review load, so one huge pull request weighs more than a
+// few typo fixes. This is the default strategy.
+type LeastLoadedSelector struct{}
+
+// Select orders the candidates by size-weighted load; candidates with equal load are picked randomly,
+// weighted by recent pairings.
+func (LeastLoadedSelector) Select(pool ReviewerPool, count int) []domain.User {
+	valid := weightedShuffle(pool.Candidates, pool.RecentPairings)
+	sort.SliceStable(valid, func(i, j int) bool {
+		return pool.Load[valid[i].ID] < pool.Load[valid[j].ID]
+	})
+
+	return firstN(valid, count)
+}
+
+// weightedShuffle returns the candidates in random order where a candidate with n recent pairings has weight
+// 1/(1+n). Without pairings this is a uniform shuffle.
+func weightedShuffle(candidates []domain.User, pairings map[string]int) []domain.User {
+	// sorting by rand^(1/weight) samples without replacement proportionally to weight (Efraimidis-Spirakis)
+	keys := make(map[string]float64, len(candidates))
+	for _, u := range candidates {
+		keys[u.ID] = math.Pow(rand.Float64(), float64(1+pairings[u.ID]))
+	}
+
+	shuffled := append([]domain.User(nil), candidates...)
+	sort.SliceStable(shuffled, func(i, j int) bool { return keys[shuffled[i].ID] > keys[shuffled[j].ID] })
+	return shuffled
+}
+
+// firstN returns at most n leading users.
+func firstN(users []domain.User, n int) []domain.User {
+	if n < 0 {
+		n = 0
+	}
+	if len(users) < n {
+		return users
+	}
+	return users[:n]
+}
+
+// excludeUsers returns candidates whose ids are not in the excluded list.
+func excludeUsers(candidates []domain.User, excluded ...string) []domain.User {
+	var valid []domain.User
+	for _, u := range candidates {
+		skip := false
+		for _, id := range excluded {
+			if u.ID == id {
+				skip = true
+				break
+			}
+		}
+		if !skip {
+			valid = append(valid, u)
+		}
+	}
+	return valid
+}
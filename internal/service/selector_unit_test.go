@@ -0,0 +1,161 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/neizhmak/avito-review-service/internal/domain"
+)
+
+func testPool(teamName string, ids ...string) ReviewerPool {
+	pool := ReviewerPool{TeamName: teamName, OpenReviews: map[string]int{}, Load: map[string]int{}}
+	for _, id := range ids {
+		pool.Candidates = append(pool.Candidates, domain.User{ID: id, IsActive: true, TeamName: teamName})
+	}
+	return pool
+}
+
+func userIDs(users []domain.User) []string {
+	ids := make([]string, 0, len(users))
+	for _, u := range users {
+		ids = append(ids, u.ID)
+	}
+	return ids
+}
+
+func TestRandomSelector_Select(t *testing.T) {
+	pool := testPool("team", "u1", "u2", "u3")
+
+	picked := RandomSelector{}.Select(pool, 2)
+	if len(picked) != 2 {
+		t.Fatalf("expected 2 reviewers, got %v", userIDs(picked))
+	}
+	if picked[0].ID == picked[1].ID {
+		t.Fatalf("expected distinct reviewers, got %v", userIDs(picked))
+	}
+
+	if got := (RandomSelector{}).Select(pool, 5); len(got) != 3 {
+		t.Fatalf("expected all 3 candidates when count exceeds pool, got %v", userIDs(got))
+	}
+}
+
+func TestRoundRobinSelector_Select(t *testing.T) {
+	pool := testPool("team", "u3", "u1", "u2")
+
+	var got []string
+	for i := 0; i < 4; i++ {
+		picked := RoundRobinSelector{}.Select(pool, 1)
+		got = append(got, userIDs(picked)...)
+		pool.LastPicked = advanceRotation(pool.LastPicked, picked)
+	}
+	want := []string{"u1", "u2", "u3", "u1"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("want rotation %v, got %v", want, got)
+		}
+	}
+
+	// a removed member is skipped without resetting the rotation
+	next := RoundRobinSelector{}.Select(ReviewerPool{Candidates: testPool("team", "u1", "u3").Candidates, LastPicked: "u2"}, 1)
+	if next[0].ID != "u3" {
+		t.Fatalf("expected u3 after u2, got %v", userIDs(next))
+	}
+}
+
+func TestAdvanceRotation(t *testing.T) {
+	tests := []struct {
+		name   string
+		last   string
+		picked []string
+		want   string
+	}{
+		{name: "first pick", last: "", picked: []string{"u1", "u2"}, want: "u2"},
+		{name: "no pick", last: "u2", picked: nil, want: "u2"},
+		{name: "wrapped", last: "u2", picked: []string{"u3", "u1"}, want: "u1"},
+		// working hours and off hours picks are merged, so the rotation order is not the pick order
+		{name: "merged picks", last: "u2", picked: []string{"u1", "u4", "u3"}, want: "u1"},
+		{name: "merged without wrap", last: "u1", picked: []string{"u4", "u2"}, want: "u4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			picked := testPool("team", tt.picked...).Candidates
+			if got := advanceRotation(tt.last, picked); got != tt.want {
+				t.Fatalf("want cursor %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLeastLoadedSelector_Select(t *testing.T) {
+	pool := testPool("team", "u1", "u2", "u3", "u4")
+	pool.Load = map[string]int{"u1": 5, "u2": 0, "u3": 2, "u4": 0}
+
+	picked := userIDs(LeastLoadedSelector{}.Select(pool, 3))
+	if len(picked) != 3 {
+		t.Fatalf("expected 3 reviewers, got %v", picked)
+	}
+	idle := map[string]bool{picked[0]: true, picked[1]: true}
+	if !idle["u2"] || !idle["u4"] || picked[2] != "u3" {
+		t.Fatalf("expected idle u2 and u4 first, then u3, got %v", picked)
+	}
+}
+
+func TestLeastLoadedSelector_WeighsBySize(t *testing.T) {
+	pool := testPool("team", "u1", "u2")
+	// u1 reviews two tiny pull requests, u2 a single huge one
+	pool.OpenReviews = map[string]int{"u1": 2, "u2": 1}
+	pool.Load = map[string]int{"u1": 2 * domain.PRSizeXS.Weight(), "u2": domain.PRSizeXL.Weight()}
+
+	if picked := (LeastLoadedSelector{}).Select(pool, 1); picked[0].ID != "u1" {
+		t.Fatalf("expected u1 with the lighter load, got %v", userIDs(picked))
+	}
+}
+
+func TestLeastLoadedSelector_RandomTieBreak(t *testing.T) {
+	pool := testPool("team", "u1", "u2", "u3")
+
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		seen[LeastLoadedSelector{}.Select(pool, 1)[0].ID] = true
+	}
+	if len(seen) < 2 {
+		t.Fatalf("expected ties to be broken randomly, always got %v", seen)
+	}
+}
+
+func TestExcludeUsers(t *testing.T) {
+	pool := testPool("team", "u1", "u2", "u3")
+
+	got := excludeUsers(pool.Candidates, "u2", "missing")
+	if len(got) != 2 || got[0].ID != "u1" || got[1].ID != "u3" {
+		t.Fatalf("unexpected candidates: %v", userIDs(got))
+	}
+}
+
+func TestWeightedShuffle_PrefersFewerPairings(t *testing.T) {
+	pool := testPool("team", "u1", "u2")
+	pool.RecentPairings = map[string]int{"u1": 20}
+
+	fresh := 0
+	for i := 0; i < 1000; i++ {
+		if (RandomSelector{}).Select(pool, 1)[0].ID == "u2" {
+			fresh++
+		}
+	}
+	// u1 comes first with probability 1/22, so u2 should win the vast majority of draws
+	if fresh < 850 {
+		t.Fatalf("expected u2 to be picked most of the time, got %d of 1000", fresh)
+	}
+
+	// equal load in least_loaded is broken by pairings the same way
+	pool.Load = map[string]int{"u1": 3, "u2": 3}
+	fresh = 0
+	for i := 0; i < 1000; i++ {
+		if (LeastLoadedSelector{}).Select(pool, 1)[0].ID == "u2" {
+			fresh++
+		}
+	}
+	if fresh < 850 {
+		t.Fatalf("expected least_loaded to prefer u2 on equal load, got %d of 1000", fresh)
+	}
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/neizhmak/avito-review-service/internal/domain"
+	"github.com/neizhmak/avito-review-service/internal/storage"
+)
+
+// GetTeamSettings retrieves the reviewer assignment settings of a team.
+func (s *PRService) GetTeamSettings(ctx context.Context, teamName string) (*domain.TeamSettings, error) {
+	settings, err := s.teamStorage.GetSettings(ctx, teamName)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, notFound("team not found")
+		}
+		return nil, err
+	}
+	return settings, nil
+}
+
+// UpdateTeamSettings validates and replaces the reviewer assignment settings of a team.
+func (s *PRService) UpdateTeamSettings(ctx context.Context, settings domain.TeamSettings) (*domain.TeamSettings, error) {
+	if _, err := s.teamStorage.GetByName(ctx, settings.TeamName); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, notFound("team not found")
+		}
+		return nil, err
+	}
+
+	if err := s.validateSettings(ctx, settings); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err = s.teamStorage.SaveSettings(ctx, tx, settings); err != nil {
+		return nil, fmt.Errorf("failed to save team settings: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit tx: %w", err)
+	}
+
+	return &settings, nil
+}
+
+// validateSettings checks that settings are consistent and reference a registered strategy and existing teams.
+func (s *PRService) validateSettings(ctx context.Context, settings domain.TeamSettings) error {
+	if settings.ReviewerCount < 1 {
+		return newServiceError(ErrCodeInvalidSettings, "reviewer_count must be at least 1")
+	}
+	if settings.MinReviewers < 0 || settings.MinReviewers > settings.ReviewerCount {
+		return newServiceError(ErrCodeInvalidSettings, "min_reviewers must be between 0 and reviewer_count")
+	}
+	if settings.RequiredApprovals < 0 || settings.RequiredApprovals > settings.ReviewerCount {
+		return newServiceError(ErrCodeInvalidSettings, "required_approvals must be between 0 and reviewer_count")
+	}
+	if settings.MaxOpenReviews < 0 {
+		return newServiceError(ErrCodeInvalidSettings, "max_open_reviews must not be negative")
+	}
+	for size, count := range settings.ReviewerCountBySize {
+		if !size.IsValid() {
+			return newServiceError(ErrCodeInvalidSettings, "unknown size "+string(size)+" in reviewer_count_by_size")
+		}
+		if count < 1 || count < settings.MinReviewers || count < settings.RequiredApprovals {
+			return newServiceError(ErrCodeInvalidSettings,
+				"reviewer_count_by_size must be at least 1, min_reviewers and required_approvals")
+		}
+	}
+	if settings.ReviewSLAHours < 0 {
+		return newServiceError(ErrCodeInvalidSettings, "review_sla_hours must not be negative")
+	}
+	if settings.SLAEscalation != "" && !settings.SLAEscalation.IsValid() {
+		return newServiceError(ErrCodeInvalidSettings, "sla_escalation must be one of NOTIFY, REASSIGN, ADD_LEAD")
+	}
+	if settings.PairingWindowDays < 0 {
+		return newServiceError(ErrCodeInvalidSettings, "pairing_window_days must not be negative")
+	}
+	if _, ok := s.selectors[settings.ReviewerStrategy]; !ok {
+		return newServiceError(ErrCodeUnknownStrategy, "unknown reviewer_strategy")
+	}
+
+	seen := make(map[string]bool, len(settings.FallbackTeams))
+	for _, fallback := range settings.FallbackTeams {
+		if fallback == settings.TeamName {
+			return newServiceError(ErrCodeInvalidSettings, "team cannot be its own fallback")
+		}
+		if seen[fallback] {
+			return newServiceError(ErrCodeInvalidSettings, "duplicate fallback team "+fallback)
+		}
+		seen[fallback] = true
+
+		if _, err := s.teamStorage.GetByName(ctx, fallback); err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				return newServiceError(ErrCodeInvalidSettings, "fallback team "+fallback+" not found")
+			}
+			return err
+		}
+	}
+	return nil
+}
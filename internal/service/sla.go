@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/neizhmak/avito-review-service/internal/domain"
+	"github.com/neizhmak/avito-review-service/internal/storage"
+)
+
+// reviewSLAHours returns the review SLA of the team of the pull request author, 0 if it has none.
+func (s *PRService) reviewSLAHours(ctx context.Context, authorID string) (int, error) {
+	author, err := s.userStorage.GetByID(ctx, authorID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get author: %w", err)
+	}
+	settings, err := s.teamStorage.GetSettings(ctx, author.TeamName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get team settings: %w", err)
+	}
+	return settings.ReviewSLAHours, nil
+}
+
+// reviewDeadline returns when a review assigned to the reviewer at the given moment is due, counting only the
+// reviewer's own working hours outside the holidays of their team.
+func (s *PRService) reviewDeadline(ctx context.Context, reviewer domain.User, assignedAt time.Time, hours int) (time.Time, error) {
+	holidays, err := s.teamStorage.GetHolidays(ctx, reviewer.TeamName)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return newWorkCalendar(reviewer, holidays).addWorkingHours(assignedAt, hours), nil
+}
+
+// GetOverdueReviews lists pending reviews of OPEN pull requests that are past their deadline, most overdue first.
+// If teamName is set only pull requests of that team's authors are listed.
+func (s *PRService) GetOverdueReviews(ctx context.Context, teamName string) ([]domain.OverdueReview, error) {
+	return s.prStorage.GetOverdueReviews(ctx, teamName, time.Now())
+}
+
+// EscalateOverdueReviews escalates every overdue review that has not been escalated yet, following the
+// sla_escalation setting of the author team. A review that cannot be reassigned, or whose team has no lead
+// to add, is escalated by notification only. Every escalation is recorded in the pull request history and
+// published to webhook subscribers as review.overdue. A review that fails to escalate is logged and skipped. It returns
+// the escalations made.
+func (s *PRService) EscalateOverdueReviews(ctx context.Context) ([]domain.ReviewEscalation, error) {
+	overdue, err := s.prStorage.GetOverdueReviews(ctx, "", time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	escalations := make([]domain.ReviewEscalation, 0)
+	for _, review := range overdue {
+		if review.EscalatedAt != nil {
+			continue
+		}
+
+		escalation, err := s.escalateReview(ctx, review)
+		if err != nil {
+			slog.Error("failed to escalate review",
+				"pull_request_id", review.PRID,
+				"reviewer_id", review.ReviewerID,
+				"error", err,
+			)
+			continue
+		}
+		if escalation == nil {
+			// approved, removed or escalated by another worker in the meantime
+			continue
+		}
+		escalations = append(escalations, *escalation)
+		slog.Info("review escalated",
+			"pull_request_id", escalation.PRID,
+			"reviewer_id", escalation.ReviewerID,
+			"action", escalation.Action,
+			"new_reviewer_id", escalation.NewReviewerID,
+		)
+	}
+	return escalations, nil
+}
+
+// escalateReview applies the author team's escalation to one overdue review. The review is claimed first inside
+// the transaction; if it is no longer pending or was escalated already, nothing is done and nil is returned.
+func (s *PRService) escalateReview(ctx context.Context, review domain.OverdueReview) (*domain.ReviewEscalation, error) {
+	settings, err := s.teamStorage.GetSettings(ctx, review.TeamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team settings: %w", err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err = s.prStorage.MarkEscalated(ctx, tx, review.PRID, review.ReviewerID); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	pr, err := s.prStorage.GetByID(ctx, review.PRID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pr: %w", err)
+	}
+
+	var picked []assignment
+	switch settings.SLAEscalation {
+	case domain.EscalationReassign:
+		reviewer, err := s.userStorage.GetByID(ctx, review.ReviewerID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get reviewer: %w", err)
+		}
+		if picked, err = s.pickReplacement(ctx, *pr, *reviewer, nil); err != nil {
+			return nil, err
+		}
+	case domain.EscalationAddLead:
+		if picked, err = s.pickLead(ctx, *pr, *settings); err != nil {
+			return nil, err
+		}
+	}
+
+	escalation := &domain.ReviewEscalation{PRID: pr.ID, ReviewerID: review.ReviewerID, Action: domain.EscalationNotify}
+	if len(picked) > 0 {
+		escalation.Action = settings.SLAEscalation
+		escalation.NewReviewerID = picked[0].User.ID
+	}
+
+	if escalation.Action == domain.EscalationReassign {
+		if err = s.prStorage.DeleteReviewer(ctx, tx, pr.ID, review.ReviewerID); err != nil {
+			return nil, err
+		}
+	}
+	if err = s.saveAssignments(ctx, tx, *pr, picked, "review overdue"); err != nil {
+		return nil, err
+	}
+	event := domain.PREvent{PRID: pr.ID, Type: domain.PREventEscalated, ReviewerID: review.ReviewerID, Reason: string(escalation.Action)}
+	if err = s.recordEvent(ctx, tx, event); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit tx: %w", err)
+	}
+	return escalation, nil
+}
+
+// pickLead picks a lead of the author team who is not yet reviewing the pull request.
+func (s *PRService) pickLead(ctx context.Context, pr domain.PullRequest, settings domain.TeamSettings) ([]assignment, error) {
+	barred, err := s.barredReviewers(ctx, pr)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.userStorage.GetActiveUsersByTeam(ctx, settings.TeamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get candidates: %w", err)
+	}
+
+	exclude := append(append([]string{pr.AuthorID}, pr.ReviewerIDs()...), barred...)
+	var leads []domain.User
+	for _, u := range excludeUsers(members, exclude...) {
+		if u.Role == domain.RoleLead {
+			leads = append(leads, u)
+		}
+	}
+
+	users, err := s.pickReviewers(ctx, settings, pr.AuthorID, leads, 1)
+	if err != nil || len(users) == 0 {
+		return nil, err
+	}
+	return []assignment{{User: users[0], Strategy: string(s.strategyFor(settings)), Reason: "team lead"}}, nil
+}
+
+// RunEscalationWorker calls EscalateOverdueReviews every interval until ctx is cancelled.
+func (s *PRService) RunEscalationWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.EscalateOverdueReviews(ctx); err != nil {
+				slog.Error("failed to escalate overdue reviews", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,323 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/neizhmak/avito-review-service/internal/domain"
+	"github.com/neizhmak/avito-review-service/internal/storage"
+)
+
+// Webhook delivery: every attempt has webhookTimeout to get a 2xx answer; failed deliveries are retried after
+// webhookRetryBase, doubling each time, and given up after webhookMaxAttempts attempts. A worker run sends at most
+// webhookBatchSize deliveries, claiming each one for webhookLease while it is being sent.
+const (
+	webhookTimeout     = 10 * time.Second
+	webhookLease       = 2 * webhookTimeout
+	webhookRetryBase   = 30 * time.Second
+	webhookMaxAttempts = 8
+	webhookBatchSize   = 100
+	webhookLogLimit    = 100
+)
+
+// Headers sent with every webhook delivery.
+const (
+	webhookEventHeader     = "X-Webhook-Event"
+	webhookDeliveryHeader  = "X-Webhook-Delivery"
+	webhookSignatureHeader = "X-Webhook-Signature-256"
+)
+
+// errWebhooksDisabled is returned by the webhook methods of a service without webhook storage.
+var errWebhooksDisabled = errors.New("webhooks are not enabled")
+
+// webhookBackoff returns how long to wait before retrying a delivery that has failed attempts times.
+func webhookBackoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	return webhookRetryBase << (attempts - 1)
+}
+
+// isInternalIP reports whether webhooks may only reach the address when private targets are allowed.
+func isInternalIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast()
+}
+
+// validateWebhookURL checks that a webhook URL is an absolute http or https URL. Unless allowPrivate is set, it
+// must not name localhost or an internal IP address.
+func validateWebhookURL(raw string, allowPrivate bool) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return newServiceError(ErrCodeInvalidWebhook, "url must be an absolute http or https URL")
+	}
+	if allowPrivate {
+		return nil
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return newServiceError(ErrCodeInvalidWebhook, "url must not point to localhost")
+	}
+	if ip := net.ParseIP(host); ip != nil && isInternalIP(ip) {
+		return newServiceError(ErrCodeInvalidWebhook, "url must not point to a loopback, link-local or private address")
+	}
+	return nil
+}
+
+// newWebhookClient returns the HTTP client used to send webhooks. Unless allowPrivate is set, it refuses to connect
+// to internal IP addresses; the check runs on the resolved address, so host names pointing inside the network and
+// redirects are refused too.
+func newWebhookClient(allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: webhookTimeout}
+	if !allowPrivate {
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || isInternalIP(ip) {
+				return fmt.Errorf("webhook address %s is not public", host)
+			}
+			return nil
+		}
+	}
+
+	return &http.Client{
+		Timeout: webhookTimeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: webhookTimeout,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
+
+// signPayload returns the signature of a webhook body: "sha256=" followed by the hex HMAC-SHA256 of the body
+// keyed with the webhook secret.
+func signPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// CreateWebhook subscribes a URL to the given event types. Without a secret a random one is generated; the
+// returned webhook is the only place the secret is shown.
+func (s *PRService) CreateWebhook(ctx context.Context, webhook domain.Webhook) (*domain.Webhook, error) {
+	if s.webhookStorage == nil {
+		return nil, errWebhooksDisabled
+	}
+	if err := validateWebhookURL(webhook.URL, s.allowPrivateWebhooks); err != nil {
+		return nil, err
+	}
+	if len(webhook.EventTypes) == 0 {
+		return nil, newServiceError(ErrCodeInvalidWebhook, "event_types must not be empty")
+	}
+
+	types := make([]domain.WebhookEventType, 0, len(webhook.EventTypes))
+	for _, t := range webhook.EventTypes {
+		if !t.IsValid() {
+			return nil, newServiceError(ErrCodeInvalidWebhook, "unknown event type "+string(t))
+		}
+		if !(domain.Webhook{EventTypes: types}).Subscribes(t) {
+			types = append(types, t)
+		}
+	}
+	webhook.EventTypes = types
+
+	if webhook.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("failed to generate secret: %w", err)
+		}
+		webhook.Secret = hex.EncodeToString(secret)
+	}
+
+	return s.webhookStorage.Create(ctx, webhook)
+}
+
+// ListWebhooks lists the webhook subscriptions without their secrets.
+func (s *PRService) ListWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	if s.webhookStorage == nil {
+		return nil, errWebhooksDisabled
+	}
+	return s.webhookStorage.List(ctx)
+}
+
+// DeleteWebhook removes a webhook subscription; deliveries that were not sent yet are dropped.
+func (s *PRService) DeleteWebhook(ctx context.Context, id int64) error {
+	if s.webhookStorage == nil {
+		return errWebhooksDisabled
+	}
+	if err := s.webhookStorage.Delete(ctx, id); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return notFound("webhook not found")
+		}
+		return err
+	}
+	return nil
+}
+
+// GetWebhookDeliveries lists the latest deliveries of a webhook, newest first.
+func (s *PRService) GetWebhookDeliveries(ctx context.Context, webhookID int64) ([]domain.WebhookDelivery, error) {
+	if s.webhookStorage == nil {
+		return nil, errWebhooksDisabled
+	}
+	if _, err := s.webhookStorage.GetByID(ctx, webhookID); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, notFound("webhook not found")
+		}
+		return nil, err
+	}
+	return s.webhookStorage.GetDeliveries(ctx, webhookID, webhookLogLimit)
+}
+
+// RedeliverWebhook queues the payload of an earlier delivery again. The new delivery is sent by the next run of
+// the webhook worker with a fresh set of attempts; the original one stays in the log.
+func (s *PRService) RedeliverWebhook(ctx context.Context, deliveryID int64) (*domain.WebhookDelivery, error) {
+	if s.webhookStorage == nil {
+		return nil, errWebhooksDisabled
+	}
+	delivery, err := s.webhookStorage.Redeliver(ctx, deliveryID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, notFound("delivery not found")
+		}
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// publish queues an event for the webhooks subscribed to it. Deliveries are stored through the executor, so
+// they are only sent if the change that caused the event is committed. Without webhook storage it does nothing.
+func (s *PRService) publish(ctx context.Context, executor storage.QueryExecutor, eventType domain.WebhookEventType, data interface{}) error {
+	if s.webhookStorage == nil {
+		return nil
+	}
+	body, err := json.Marshal(domain.WebhookPayload{Event: eventType, CreatedAt: time.Now().UTC(), Data: data})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+	return s.webhookStorage.EnqueueDeliveries(ctx, executor, eventType, body)
+}
+
+// DeliverWebhooks sends up to webhookBatchSize webhook deliveries that are due and records the outcome of each
+// attempt. Deliveries are claimed one at a time, so the claim of a delivery only has to outlast its own attempt
+// and concurrent workers do not send it twice. It returns how many deliveries succeeded.
+func (s *PRService) DeliverWebhooks(ctx context.Context) (int, error) {
+	if s.webhookStorage == nil {
+		return 0, nil
+	}
+
+	webhooks := make(map[int64]*domain.Webhook)
+	delivered := 0
+	for i := 0; i < webhookBatchSize && ctx.Err() == nil; i++ {
+		claimed, err := s.webhookStorage.ClaimDueDeliveries(ctx, time.Now(), 1, webhookLease)
+		if err != nil {
+			return delivered, err
+		}
+		if len(claimed) == 0 {
+			break
+		}
+		d := claimed[0]
+
+		webhook, ok := webhooks[d.WebhookID]
+		if !ok {
+			webhook, err = s.webhookStorage.GetByID(ctx, d.WebhookID)
+			if errors.Is(err, storage.ErrNotFound) {
+				// deleted in the meantime together with its deliveries
+				continue
+			}
+			if err != nil {
+				return delivered, err
+			}
+			webhooks[d.WebhookID] = webhook
+		}
+
+		now := time.Now()
+		d.Attempts++
+		d.LastStatusCode, err = s.sendWebhook(ctx, *webhook, d)
+		if err == nil {
+			d.Status = domain.DeliveryDelivered
+			d.LastError = ""
+			d.DeliveredAt = &now
+			delivered++
+		} else {
+			d.LastError = err.Error()
+			d.NextAttemptAt = now.Add(webhookBackoff(d.Attempts))
+			if d.Attempts >= webhookMaxAttempts {
+				d.Status = domain.DeliveryFailed
+			}
+			slog.Warn("webhook delivery failed",
+				"delivery_id", d.ID,
+				"webhook_id", d.WebhookID,
+				"attempts", d.Attempts,
+				"error", err,
+			)
+		}
+
+		if err = s.webhookStorage.UpdateDelivery(ctx, d); err != nil {
+			return delivered, err
+		}
+	}
+	return delivered, nil
+}
+
+// sendWebhook posts a delivery to the webhook URL and returns the response status code. Any status other than
+// 2xx is an error.
+func (s *PRService) sendWebhook(ctx context.Context, webhook domain.Webhook, delivery domain.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhookEventHeader, string(delivery.EventType))
+	req.Header.Set(webhookDeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(webhookSignatureHeader, signPayload(webhook.Secret, delivery.Payload))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// RunWebhookWorker calls DeliverWebhooks every interval until ctx is cancelled.
+func (s *PRService) RunWebhookWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.DeliverWebhooks(ctx); err != nil {
+				slog.Error("failed to deliver webhooks", "error", err)
+			}
+		}
+	}
+}
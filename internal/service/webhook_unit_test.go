@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/neizhmak/avito-review-service/internal/domain"
+)
+
+func TestSignPayload(t *testing.T) {
+	got := signPayload("key", []byte("The quick brown fox jumps over the lazy dog"))
+	want := "sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"
+	if got != want {
+		t.Fatalf("want %s, got %s", want, got)
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 0, want: 30 * time.Second},
+		{attempts: 1, want: 30 * time.Second},
+		{attempts: 2, want: time.Minute},
+		{attempts: 4, want: 4 * time.Minute},
+		{attempts: 7, want: 32 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := webhookBackoff(tt.attempts); got != tt.want {
+			t.Fatalf("attempts %d: want %v, got %v", tt.attempts, tt.want, got)
+		}
+	}
+}
+
+func TestSendWebhook(t *testing.T) {
+	var (
+		gotBody      string
+		gotHeaders   http.Header
+		answerStatus = http.StatusNoContent
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotBody, gotHeaders = string(body), r.Header
+		w.WriteHeader(answerStatus)
+	}))
+	defer server.Close()
+
+	s := &PRService{httpClient: server.Client()}
+	webhook := domain.Webhook{URL: server.URL, Secret: "s3cret"}
+	delivery := domain.WebhookDelivery{ID: 42, EventType: domain.WebhookPRMerged, Payload: []byte(`{"event":"pr.merged"}`)}
+
+	status, err := s.sendWebhook(context.Background(), webhook, delivery)
+	if err != nil || status != http.StatusNoContent {
+		t.Fatalf("expected a successful delivery, got %d %v", status, err)
+	}
+	if gotBody != `{"event":"pr.merged"}` {
+		t.Fatalf("unexpected body %s", gotBody)
+	}
+	if gotHeaders.Get(webhookSignatureHeader) != signPayload("s3cret", []byte(gotBody)) {
+		t.Fatalf("unexpected signature %s", gotHeaders.Get(webhookSignatureHeader))
+	}
+	if gotHeaders.Get(webhookEventHeader) != "pr.merged" || gotHeaders.Get(webhookDeliveryHeader) != "42" {
+		t.Fatalf("unexpected headers %v", gotHeaders)
+	}
+
+	answerStatus = http.StatusInternalServerError
+	if status, err = s.sendWebhook(context.Background(), webhook, delivery); err == nil || status != http.StatusInternalServerError {
+		t.Fatalf("expected a failed delivery, got %d %v", status, err)
+	}
+}
+
+func TestValidateWebhookURL(t *testing.T) {
+	tests := []struct {
+		url          string
+		allowPrivate bool
+		wantErr      bool
+	}{
+		{url: "https://ci.example.com/hooks", wantErr: false},
+		{url: "http://203.0.113.10:8080/hooks", wantErr: false},
+		{url: "ftp://ci.example.com/hooks", wantErr: true},
+		{url: "/hooks", wantErr: true},
+		{url: "http://localhost:8080/hooks", wantErr: true},
+		{url: "http://api.localhost/hooks", wantErr: true},
+		{url: "http://127.0.0.1/hooks", wantErr: true},
+		{url: "http://10.1.2.3/hooks", wantErr: true},
+		{url: "http://192.168.0.5/hooks", wantErr: true},
+		{url: "http://169.254.169.254/latest/meta-data", wantErr: true},
+		{url: "http://[::1]:8080/hooks", wantErr: true},
+		{url: "http://0.0.0.0/hooks", wantErr: true},
+		{url: "http://127.0.0.1/hooks", allowPrivate: true, wantErr: false},
+		{url: "http://localhost:8080/hooks", allowPrivate: true, wantErr: false},
+	}
+
+	for _, tt := range tests {
+		err := validateWebhookURL(tt.url, tt.allowPrivate)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%s (allow private %v): unexpected error %v", tt.url, tt.allowPrivate, err)
+		}
+	}
+}
+
+func TestWebhookClient_RefusesInternalAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	webhook := domain.Webhook{URL: server.URL, Secret: "s3cret"}
+	delivery := domain.WebhookDelivery{ID: 1, EventType: domain.WebhookPRMerged, Payload: []byte(`{}`)}
+
+	s := &PRService{httpClient: newWebhookClient(false)}
+	if _, err := s.sendWebhook(context.Background(), webhook, delivery); err == nil {
+		t.Fatalf("expected delivery to a loopback address to be refused")
+	}
+
+	s = &PRService{httpClient: newWebhookClient(true)}
+	if status, err := s.sendWebhook(context.Background(), webhook, delivery); err != nil || status != http.StatusNoContent {
+		t.Fatalf("expected delivery with private targets allowed, got %d %v", status, err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/neizhmak/avito-review-service/internal/domain"
 	"github.com/neizhmak/avito-review-service/internal/storage"
 )
@@ -18,15 +19,43 @@ func NewPullRequestStorage(db *sql.DB) *PullRequestStorage {
 	return &PullRequestStorage{db: db}
 }
 
-// Save saves a new pr to the database.
+// Save saves a new pr to the database along with its changed files and reviewer preferences.
 func (s *PullRequestStorage) Save(ctx context.Context, executor storage.QueryExecutor, pr domain.PullRequest) error {
-	query := "INSERT INTO pull_requests (id, title, author_id, status) VALUES ($1, $2, $3, $4)"
+	query := `
+		INSERT INTO pull_requests (
+			id, title, author_id, status, requested_reviewers, excluded_reviewers, labels,
+			lines_added, lines_removed, files_changed, size
+		)
+		VALUES (
+			$1, $2, $3, $4, COALESCE($5::text[], '{}'), COALESCE($6::text[], '{}'), COALESCE($7::text[], '{}'),
+			$8, $9, $10, NULLIF($11, '')
+		)
+	`
 
-	_, err := executor.ExecContext(ctx, query, pr.ID, pr.Title, pr.AuthorID, pr.Status)
+	var added, removed, files sql.NullInt64
+	if pr.DiffStats != nil {
+		added = sql.NullInt64{Int64: int64(pr.DiffStats.LinesAdded), Valid: true}
+		removed = sql.NullInt64{Int64: int64(pr.DiffStats.LinesRemoved), Valid: true}
+		files = sql.NullInt64{Int64: int64(pr.DiffStats.FilesChanged), Valid: true}
+	}
+	_, err := executor.ExecContext(ctx, query, pr.ID, pr.Title, pr.AuthorID, pr.Status,
+		pq.Array(pr.RequestedReviewers), pq.Array(pr.ExcludedReviewers), pq.Array(pr.Labels),
+		added, removed, files, string(pr.Size))
 	if err != nil {
 		return fmt.Errorf("failed to insert pr: %w", err)
 	}
 
+	if len(pr.ChangedFiles) > 0 {
+		query = `
+			INSERT INTO pull_request_files (pull_request_id, path)
+			SELECT $1, unnest($2::text[])
+			ON CONFLICT DO NOTHING
+		`
+		if _, err = executor.ExecContext(ctx, query, pr.ID, pq.Array(pr.ChangedFiles)); err != nil {
+			return fmt.Errorf("failed to insert pr files: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -37,16 +66,39 @@ func (s *PullRequestStorage) SaveReviewer(ctx context.Context, executor storage.
 	return err
 }
 
+// SaveFallbackReviewer assigns a reviewer borrowed from a fallback team to a pull request.
+func (s *PullRequestStorage) SaveFallbackReviewer(ctx context.Context, executor storage.QueryExecutor, prID, reviewerID, fallbackTeam string) error {
+	query := "INSERT INTO pr_reviewers (pull_request_id, reviewer_id, fallback_team) VALUES ($1, $2, $3)"
+	_, err := executor.ExecContext(ctx, query, prID, reviewerID, fallbackTeam)
+	return err
+}
+
+// SaveShadowReviewer assigns a non-blocking shadow reviewer to a pull request.
+func (s *PullRequestStorage) SaveShadowReviewer(ctx context.Context, executor storage.QueryExecutor, prID, reviewerID string) error {
+	query := "INSERT INTO pr_reviewers (pull_request_id, reviewer_id, shadow) VALUES ($1, $2, TRUE)"
+	_, err := executor.ExecContext(ctx, query, prID, reviewerID)
+	return err
+}
+
 // GetByID retrieves a pull request by its ID.
 func (s *PullRequestStorage) GetByID(ctx context.Context, id string) (*domain.PullRequest, error) {
-	query := "SELECT id, title, author_id, status, created_at, merged_at FROM pull_requests WHERE id = $1"
+	query := `
+		SELECT id, title, author_id, status, created_at, merged_at, closed_at, force_merged, needs_reviewers,
+			requested_reviewers, excluded_reviewers, labels, lines_added, lines_removed, files_changed, size
+		FROM pull_requests
+		WHERE id = $1
+	`
 
 	row := s.db.QueryRowContext(ctx, query, id)
 
 	var pr domain.PullRequest
 	var createdAt time.Time
-	var mergedAt sql.NullTime
-	err := row.Scan(&pr.ID, &pr.Title, &pr.AuthorID, &pr.Status, &createdAt, &mergedAt)
+	var mergedAt, closedAt sql.NullTime
+	var added, removed, files sql.NullInt64
+	var size sql.NullString
+	err := row.Scan(&pr.ID, &pr.Title, &pr.AuthorID, &pr.Status, &createdAt, &mergedAt, &closedAt, &pr.ForceMerged, &pr.NeedsReviewers,
+		pq.Array(&pr.RequestedReviewers), pq.Array(&pr.ExcludedReviewers), pq.Array(&pr.Labels),
+		&added, &removed, &files, &size)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("%w: pr", ErrNotFound)
@@ -58,32 +110,188 @@ func (s *PullRequestStorage) GetByID(ctx context.Context, id string) (*domain.Pu
 	if mergedAt.Valid {
 		pr.MergedAt = &mergedAt.Time
 	}
+	if closedAt.Valid {
+		pr.ClosedAt = &closedAt.Time
+	}
+	if added.Valid {
+		pr.DiffStats = &domain.DiffStats{
+			LinesAdded:   int(added.Int64),
+			LinesRemoved: int(removed.Int64),
+			FilesChanged: int(files.Int64),
+		}
+	}
+	pr.Size = domain.PRSize(size.String)
 
-	reviewers, err := s.GetReviewers(ctx, id)
+	pr.Reviewers, err = s.GetReviewerAssignments(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get reviewers: %w", err)
 	}
-	pr.Reviewers = reviewers
+
+	query = "SELECT COALESCE(array_agg(path ORDER BY path), '{}') FROM pull_request_files WHERE pull_request_id = $1"
+	if err = s.db.QueryRowContext(ctx, query, id).Scan(pq.Array(&pr.ChangedFiles)); err != nil {
+		return nil, fmt.Errorf("failed to get pr files: %w", err)
+	}
 
 	return &pr, nil
 }
 
-// UpdateStatus updates the status of a pull request, setting merged_at if status is MERGED.
-func (s *PullRequestStorage) UpdateStatus(ctx context.Context, executor storage.QueryExecutor, id string, status domain.PRStatus) error {
+// GetReviewerAssignments retrieves the reviewers of a pull request along with their review states.
+func (s *PullRequestStorage) GetReviewerAssignments(ctx context.Context, prID string) ([]domain.AssignedReviewer, error) {
+	query := `
+		SELECT reviewer_id, state, assigned_at, state_updated_at, COALESCE(fallback_team, ''), shadow, due_at, escalated_at
+		FROM pr_reviewers
+		WHERE pull_request_id = $1
+		ORDER BY assigned_at, reviewer_id
+	`
+	rows, err := s.db.QueryContext(ctx, query, prID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reviewers: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	reviewers := make([]domain.AssignedReviewer, 0)
+	for rows.Next() {
+		var (
+			r                  domain.AssignedReviewer
+			assignedAt         time.Time
+			stateUpdatedAt     sql.NullTime
+			dueAt, escalatedAt sql.NullTime
+		)
+		if err := rows.Scan(&r.UserID, &r.State, &assignedAt, &stateUpdatedAt, &r.FallbackTeam, &r.Shadow, &dueAt, &escalatedAt); err != nil {
+			return nil, err
+		}
+		r.AssignedAt = &assignedAt
+		if stateUpdatedAt.Valid {
+			r.StateUpdatedAt = &stateUpdatedAt.Time
+		}
+		if dueAt.Valid {
+			r.DueAt = &dueAt.Time
+		}
+		if escalatedAt.Valid {
+			r.EscalatedAt = &escalatedAt.Time
+		}
+		reviewers = append(reviewers, r)
+	}
+	return reviewers, rows.Err()
+}
+
+// UpdateReviewState records the review outcome of an assigned reviewer.
+func (s *PullRequestStorage) UpdateReviewState(ctx context.Context, executor storage.QueryExecutor, prID, reviewerID string, state domain.ReviewState) error {
+	query := "UPDATE pr_reviewers SET state = $1, state_updated_at = NOW() WHERE pull_request_id = $2 AND reviewer_id = $3"
+	res, err := executor.ExecContext(ctx, query, state, prID, reviewerID)
+	if err != nil {
+		return fmt.Errorf("failed to update review state: %w", err)
+	}
+
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("%w: reviewer not found on this PR", ErrNotFound)
+	}
+	return nil
+}
+
+// UpdateStatus moves a pull request from one of the given statuses to status, setting merged_at if status is MERGED
+// and closed_at if status is CLOSED. Any other status clears closed_at. It returns ErrNotFound if the pull request
+// is not in one of the given statuses.
+func (s *PullRequestStorage) UpdateStatus(
+	ctx context.Context, executor storage.QueryExecutor, id string, from []domain.PRStatus, status domain.PRStatus,
+) error {
 	var query string
-	if status == domain.PRStatusMerged {
-		query = "UPDATE pull_requests SET status = $1, merged_at = NOW() WHERE id = $2"
-	} else {
-		query = "UPDATE pull_requests SET status = $1 WHERE id = $2"
+	switch status {
+	case domain.PRStatusMerged:
+		query = "UPDATE pull_requests SET status = $1, merged_at = NOW() WHERE id = $2 AND status = ANY($3)"
+	case domain.PRStatusClosed:
+		query = "UPDATE pull_requests SET status = $1, closed_at = NOW() WHERE id = $2 AND status = ANY($3)"
+	default:
+		query = "UPDATE pull_requests SET status = $1, closed_at = NULL WHERE id = $2 AND status = ANY($3)"
 	}
 
-	_, err := executor.ExecContext(ctx, query, status, id)
+	allowed := make([]string, 0, len(from))
+	for _, st := range from {
+		allowed = append(allowed, string(st))
+	}
+	res, err := executor.ExecContext(ctx, query, status, id, pq.Array(allowed))
 	if err != nil {
 		return fmt.Errorf("failed to update pr status: %w", err)
 	}
+
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("%w: pr with status %v", ErrNotFound, from)
+	}
 	return nil
 }
 
+// MarkForceMerged records that a pull request was merged bypassing the merge policy.
+func (s *PullRequestStorage) MarkForceMerged(ctx context.Context, executor storage.QueryExecutor, id string) error {
+	_, err := executor.ExecContext(ctx, "UPDATE pull_requests SET force_merged = TRUE WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to mark pr force merged: %w", err)
+	}
+	return nil
+}
+
+// SetNeedsReviewers marks or unmarks a pull request as waiting for more reviewers.
+func (s *PullRequestStorage) SetNeedsReviewers(ctx context.Context, executor storage.QueryExecutor, id string, needs bool) error {
+	_, err := executor.ExecContext(ctx, "UPDATE pull_requests SET needs_reviewers = $1 WHERE id = $2", needs, id)
+	if err != nil {
+		return fmt.Errorf("failed to update pr needs_reviewers: %w", err)
+	}
+	return nil
+}
+
+// LockNeedingReviewers locks the pull request row until the end of the executor's transaction and reports whether
+// the pull request is still OPEN and waiting for more reviewers.
+func (s *PullRequestStorage) LockNeedingReviewers(ctx context.Context, executor storage.QueryExecutor, id string) (bool, error) {
+	query := "SELECT needs_reviewers AND status = $2 FROM pull_requests WHERE id = $1 FOR UPDATE"
+
+	var needs bool
+	if err := executor.QueryRowContext(ctx, query, id, domain.PRStatusOpen).Scan(&needs); err != nil {
+		if err == sql.ErrNoRows {
+			return false, fmt.Errorf("%w: pr", ErrNotFound)
+		}
+		return false, fmt.Errorf("failed to lock pr: %w", err)
+	}
+	return needs, nil
+}
+
+// LockStatus locks the pull request row until the end of the executor's transaction and returns its status.
+func (s *PullRequestStorage) LockStatus(ctx context.Context, executor storage.QueryExecutor, id string) (domain.PRStatus, error) {
+	var status domain.PRStatus
+	err := executor.QueryRowContext(ctx, "SELECT status FROM pull_requests WHERE id = $1 FOR UPDATE", id).Scan(&status)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("%w: pr", ErrNotFound)
+		}
+		return "", fmt.Errorf("failed to lock pr: %w", err)
+	}
+	return status, nil
+}
+
+// GetIDsNeedingReviewers returns the ids of OPEN pull requests waiting for more reviewers, oldest first.
+func (s *PullRequestStorage) GetIDsNeedingReviewers(ctx context.Context) ([]string, error) {
+	query := "SELECT id FROM pull_requests WHERE needs_reviewers AND status = $1 ORDER BY created_at, id"
+	rows, err := s.db.QueryContext(ctx, query, domain.PRStatusOpen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query prs needing reviewers: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
 // GetReviewers retrieves the list of reviewer IDs for a given pull request.
 func (s *PullRequestStorage) GetReviewers(ctx context.Context, prID string) ([]string, error) {
 	query := "SELECT reviewer_id FROM pr_reviewers WHERE pull_request_id = $1"
@@ -106,6 +314,73 @@ func (s *PullRequestStorage) GetReviewers(ctx context.Context, prID string) ([]s
 	return ids, rows.Err()
 }
 
+// SetReviewDueAt sets the review deadline of an assigned reviewer.
+func (s *PullRequestStorage) SetReviewDueAt(ctx context.Context, executor storage.QueryExecutor, prID, reviewerID string, due time.Time) error {
+	query := "UPDATE pr_reviewers SET due_at = $1 WHERE pull_request_id = $2 AND reviewer_id = $3"
+	if _, err := executor.ExecContext(ctx, query, due, prID, reviewerID); err != nil {
+		return fmt.Errorf("failed to set review due date: %w", err)
+	}
+	return nil
+}
+
+// MarkEscalated records that an overdue review has been escalated. It returns ErrNotFound unless the review is
+// still pending, not escalated yet and on an OPEN pull request, so that a review is escalated only once.
+func (s *PullRequestStorage) MarkEscalated(ctx context.Context, executor storage.QueryExecutor, prID, reviewerID string) error {
+	query := `
+		UPDATE pr_reviewers SET escalated_at = NOW()
+		WHERE pull_request_id = $1 AND reviewer_id = $2 AND escalated_at IS NULL AND state = $3
+		  AND EXISTS (SELECT 1 FROM pull_requests WHERE id = $1 AND status = $4)
+	`
+	res, err := executor.ExecContext(ctx, query, prID, reviewerID, domain.ReviewStatePending, domain.PRStatusOpen)
+	if err != nil {
+		return fmt.Errorf("failed to mark review escalated: %w", err)
+	}
+
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("%w: pending review to escalate", ErrNotFound)
+	}
+	return nil
+}
+
+// GetOverdueReviews lists the pending, non-shadow reviews of OPEN pull requests whose deadline is before the given
+// time, most overdue first. If teamName is set only pull requests of that team's authors are listed.
+func (s *PullRequestStorage) GetOverdueReviews(ctx context.Context, teamName string, at time.Time) ([]domain.OverdueReview, error) {
+	query := `
+		SELECT pr.id, pr.title, pr.author_id, u.team_name, rev.reviewer_id, rev.assigned_at, rev.due_at, rev.escalated_at
+		FROM pr_reviewers rev
+		JOIN pull_requests pr ON pr.id = rev.pull_request_id
+		JOIN users u ON u.id = pr.author_id
+		WHERE pr.status = $1 AND rev.state = $2 AND NOT rev.shadow AND rev.due_at < $3
+		  AND ($4 = '' OR u.team_name = $4)
+		ORDER BY rev.due_at, pr.id, rev.reviewer_id
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, domain.PRStatusOpen, domain.ReviewStatePending, at, teamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query overdue reviews: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	reviews := make([]domain.OverdueReview, 0)
+	for rows.Next() {
+		var (
+			r           domain.OverdueReview
+			escalatedAt sql.NullTime
+		)
+		if err := rows.Scan(&r.PRID, &r.Title, &r.AuthorID, &r.TeamName, &r.ReviewerID, &r.AssignedAt, &r.DueAt, &escalatedAt); err != nil {
+			return nil, err
+		}
+		if escalatedAt.Valid {
+			r.EscalatedAt = &escalatedAt.Time
+		}
+		reviews = append(reviews, r)
+	}
+	return reviews, rows.Err()
+}
+
 // DeleteReviewer removes a reviewer from a pull request.
 func (s *PullRequestStorage) DeleteReviewer(ctx context.Context, executor storage.QueryExecutor, prID, reviewerID string) error {
 	query := "DELETE FROM pr_reviewers WHERE pull_request_id = $1 AND reviewer_id = $2"
@@ -149,6 +424,35 @@ func (s *PullRequestStorage) GetByReviewerID(ctx context.Context, reviewerID str
 	return prs, rows.Err()
 }
 
+// GetOpenIDsByReviewerTeam returns the ids of OPEN pull requests reviewed by at least one member of the team.
+func (s *PullRequestStorage) GetOpenIDsByReviewerTeam(ctx context.Context, teamName string) ([]string, error) {
+	query := `
+		SELECT DISTINCT pr.id
+		FROM pull_requests pr
+		JOIN pr_reviewers rev ON rev.pull_request_id = pr.id
+		JOIN users u ON u.id = rev.reviewer_id
+		WHERE u.team_name = $1 AND pr.status = $2
+		ORDER BY pr.id
+	`
+	rows, err := s.db.QueryContext(ctx, query, teamName, domain.PRStatusOpen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query team review prs: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
 // RemoveReviewersByTeam removes all reviewers from open pull requests for a given team.
 func (s *PullRequestStorage) RemoveReviewersByTeam(ctx context.Context, executor storage.QueryExecutor, teamName string) error {
 	query := `
@@ -167,6 +471,161 @@ func (s *PullRequestStorage) RemoveReviewersByTeam(ctx context.Context, executor
 	return nil
 }
 
+// GetOpenReviewCountsByTeam returns the number of OPEN pull requests each member of the team is reviewing.
+// Members without open reviews are included with a zero count.
+func (s *PullRequestStorage) GetOpenReviewCountsByTeam(ctx context.Context, teamName string) (map[string]int, error) {
+	query := `
+		SELECT u.id, COUNT(pr.id)
+		FROM users u
+		LEFT JOIN pr_reviewers rev ON rev.reviewer_id = u.id
+		LEFT JOIN pull_requests pr ON pr.id = rev.pull_request_id AND pr.status = $2
+		WHERE u.team_name = $1
+		GROUP BY u.id
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, teamName, domain.PRStatusOpen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query open review counts: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var (
+			userID string
+			count  int
+		)
+		if err := rows.Scan(&userID, &count); err != nil {
+			return nil, err
+		}
+		counts[userID] = count
+	}
+	return counts, rows.Err()
+}
+
+// GetOpenReviewSizesByTeam counts, per member of the team, the OPEN pull requests they are reviewing by size.
+// Pull requests of unknown size are counted under the empty size.
+func (s *PullRequestStorage) GetOpenReviewSizesByTeam(ctx context.Context, teamName string) (map[string]map[domain.PRSize]int, error) {
+	query := `
+		SELECT rev.reviewer_id, COALESCE(pr.size, ''), COUNT(*)
+		FROM pr_reviewers rev
+		JOIN users u ON u.id = rev.reviewer_id
+		JOIN pull_requests pr ON pr.id = rev.pull_request_id
+		WHERE u.team_name = $1 AND pr.status = $2
+		GROUP BY rev.reviewer_id, pr.size
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, teamName, domain.PRStatusOpen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query open review sizes: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	sizes := make(map[string]map[domain.PRSize]int)
+	for rows.Next() {
+		var (
+			userID string
+			size   domain.PRSize
+			count  int
+		)
+		if err := rows.Scan(&userID, &size, &count); err != nil {
+			return nil, err
+		}
+		if sizes[userID] == nil {
+			sizes[userID] = make(map[domain.PRSize]int)
+		}
+		sizes[userID][size] = count
+	}
+	return sizes, rows.Err()
+}
+
+// pairedReviews selects the pull request and reviewer of every review since $2: current reviewers assigned since
+// then, and reviewers who were replaced ($3), removed or declined ($4, $5) since then. Shadow reviewers are left out.
+const pairedReviews = `
+	SELECT pull_request_id, reviewer_id FROM pr_reviewers WHERE NOT shadow AND assigned_at >= $2::timestamptz
+	UNION
+	SELECT pull_request_id, previous_reviewer_id FROM pr_events WHERE event_type = $3 AND created_at >= $2::timestamptz
+	UNION
+	SELECT pull_request_id, reviewer_id FROM pr_events
+	WHERE event_type IN ($4, $5) AND NOT shadow AND created_at >= $2::timestamptz
+`
+
+// GetRecentPairings counts, per reviewer, the author's pull requests they reviewed since the given time. Current
+// reviewers come from pr_reviewers; reviewers who were replaced, removed or declined since come from the pull
+// request history. Shadow reviewers are not counted.
+func (s *PullRequestStorage) GetRecentPairings(ctx context.Context, authorID string, since time.Time) (map[string]int, error) {
+	query := `
+		SELECT p.reviewer_id, COUNT(DISTINCT p.pull_request_id)
+		FROM (` + pairedReviews + `) p
+		JOIN pull_requests pr ON pr.id = p.pull_request_id
+		WHERE pr.author_id = $1
+		GROUP BY p.reviewer_id
+	`
+	rows, err := s.db.QueryContext(ctx, query, authorID, since,
+		domain.PREventReassigned, domain.PREventRemoved, domain.PREventDeclined)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recent pairings: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var (
+			reviewerID string
+			count      int
+		)
+		if err := rows.Scan(&reviewerID, &count); err != nil {
+			return nil, err
+		}
+		counts[reviewerID] = count
+	}
+	return counts, rows.Err()
+}
+
+// GetPairingMatrix counts, as GetRecentPairings does, the pull requests each reviewer reviewed since the given time
+// for every author of the team, keyed by author and then by reviewer.
+func (s *PullRequestStorage) GetPairingMatrix(ctx context.Context, teamName string, since time.Time) (map[string]map[string]int, error) {
+	query := `
+		SELECT pr.author_id, p.reviewer_id, COUNT(DISTINCT p.pull_request_id)
+		FROM (` + pairedReviews + `) p
+		JOIN pull_requests pr ON pr.id = p.pull_request_id
+		JOIN users u ON u.id = pr.author_id
+		WHERE u.team_name = $1
+		GROUP BY pr.author_id, p.reviewer_id
+	`
+	rows, err := s.db.QueryContext(ctx, query, teamName, since,
+		domain.PREventReassigned, domain.PREventRemoved, domain.PREventDeclined)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pairing matrix: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	matrix := make(map[string]map[string]int)
+	for rows.Next() {
+		var (
+			authorID   string
+			reviewerID string
+			count      int
+		)
+		if err := rows.Scan(&authorID, &reviewerID, &count); err != nil {
+			return nil, err
+		}
+		if matrix[authorID] == nil {
+			matrix[authorID] = make(map[string]int)
+		}
+		matrix[authorID][reviewerID] = count
+	}
+	return matrix, rows.Err()
+}
+
 // GetSystemStats retrieves overall system statistics.
 func (s *PullRequestStorage) GetSystemStats(ctx context.Context) (*domain.SystemStats, error) {
 	stats := &domain.SystemStats{}
@@ -199,5 +658,122 @@ func (s *PullRequestStorage) GetSystemStats(ctx context.Context) (*domain.System
 		stats.TopReviewers = append(stats.TopReviewers, r)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	stats.DeclinesByReason, err = s.getDeclineCounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	return stats, nil
 }
+
+// getDeclineCounts counts recorded review declines per reason.
+func (s *PullRequestStorage) getDeclineCounts(ctx context.Context) (map[domain.DeclineReason]int, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT reason, COUNT(*) FROM review_declines GROUP BY reason")
+	if err != nil {
+		return nil, fmt.Errorf("failed to count declines: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	counts := make(map[domain.DeclineReason]int)
+	for rows.Next() {
+		var (
+			reason domain.DeclineReason
+			count  int
+		)
+		if err := rows.Scan(&reason, &count); err != nil {
+			return nil, err
+		}
+		counts[reason] = count
+	}
+	return counts, rows.Err()
+}
+
+// SaveDecline records that a reviewer declined a pull request. A repeated decline replaces the earlier reason.
+func (s *PullRequestStorage) SaveDecline(ctx context.Context, executor storage.QueryExecutor, decline domain.ReviewDecline) error {
+	query := `
+		INSERT INTO review_declines (pull_request_id, user_id, reason)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (pull_request_id, user_id) DO UPDATE SET reason = EXCLUDED.reason, declined_at = NOW()
+	`
+	if _, err := executor.ExecContext(ctx, query, decline.PRID, decline.UserID, decline.Reason); err != nil {
+		return fmt.Errorf("failed to save decline: %w", err)
+	}
+	return nil
+}
+
+// GetDeclines returns the declines recorded for a pull request, oldest first.
+func (s *PullRequestStorage) GetDeclines(ctx context.Context, prID string) ([]domain.ReviewDecline, error) {
+	query := `
+		SELECT pull_request_id, user_id, reason, declined_at
+		FROM review_declines
+		WHERE pull_request_id = $1
+		ORDER BY declined_at, user_id
+	`
+	rows, err := s.db.QueryContext(ctx, query, prID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query declines: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	declines := make([]domain.ReviewDecline, 0)
+	for rows.Next() {
+		var d domain.ReviewDecline
+		if err := rows.Scan(&d.PRID, &d.UserID, &d.Reason, &d.DeclinedAt); err != nil {
+			return nil, err
+		}
+		declines = append(declines, d)
+	}
+	return declines, rows.Err()
+}
+
+// SaveEvent appends an event to the history of a pull request and returns it with its id and time set.
+func (s *PullRequestStorage) SaveEvent(ctx context.Context, executor storage.QueryExecutor, event domain.PREvent) (*domain.PREvent, error) {
+	query := `
+		INSERT INTO pr_events (pull_request_id, event_type, reviewer_id, previous_reviewer_id, actor, reason, strategy, shadow)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`
+	err := executor.QueryRowContext(ctx, query, event.PRID, event.Type, event.ReviewerID, event.PreviousReviewerID,
+		event.Actor, event.Reason, event.Strategy, event.Shadow).Scan(&event.ID, &event.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save event: %w", err)
+	}
+	return &event, nil
+}
+
+// GetEvents returns the history of a pull request in the order it was recorded.
+func (s *PullRequestStorage) GetEvents(ctx context.Context, prID string) ([]domain.PREvent, error) {
+	query := `
+		SELECT id, pull_request_id, event_type, reviewer_id, previous_reviewer_id, actor, reason, strategy, shadow, created_at
+		FROM pr_events
+		WHERE pull_request_id = $1
+		ORDER BY id
+	`
+	rows, err := s.db.QueryContext(ctx, query, prID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	events := make([]domain.PREvent, 0)
+	for rows.Next() {
+		var e domain.PREvent
+		err := rows.Scan(&e.ID, &e.PRID, &e.Type, &e.ReviewerID, &e.PreviousReviewerID, &e.Actor, &e.Reason, &e.Strategy,
+			&e.Shadow, &e.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/neizhmak/avito-review-service/internal/domain"
@@ -122,7 +123,11 @@ func TestPullRequestStorage_UpdateStatusAndGetByReviewer(t *testing.T) {
 		t.Fatalf("failed to save reviewer: %v", err)
 	}
 
-	if err := prStorage.UpdateStatus(ctx, db, prID, domain.PRStatusMerged); err != nil {
+	err := prStorage.UpdateStatus(ctx, db, prID, []domain.PRStatus{domain.PRStatusDraft}, domain.PRStatusMerged)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound when the pr is not in the expected status, got %v", err)
+	}
+	if err = prStorage.UpdateStatus(ctx, db, prID, []domain.PRStatus{domain.PRStatusOpen}, domain.PRStatusMerged); err != nil {
 		t.Fatalf("failed to update status: %v", err)
 	}
 	updated, err := prStorage.GetByID(ctx, prID)
@@ -141,3 +146,35 @@ func TestPullRequestStorage_UpdateStatusAndGetByReviewer(t *testing.T) {
 		t.Fatalf("expected PR by reviewer, got %+v", byReviewer)
 	}
 }
+
+func TestPullRequestStorage_GetOpenReviewCountsByTeam(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	ctx := context.Background()
+
+	teamStorage := NewTeamStorage(db)
+	userStorage := NewUserStorage(db)
+	prStorage := NewPullRequestStorage(db)
+
+	teamName := "storage-load"
+	testutil.CleanupTeamData(t, db, teamName)
+
+	testutil.SeedTeam(t, teamStorage, userStorage, teamName, []domain.User{
+		{ID: "load-author", Username: "Author", IsActive: true},
+		{ID: "load-busy", Username: "Busy", IsActive: true},
+		{ID: "load-idle", Username: "Idle", IsActive: true},
+	})
+	testutil.SeedPR(t, prStorage, db, domain.PullRequest{ID: "load-pr-1", Title: "One", AuthorID: "load-author"}, "load-busy")
+	testutil.SeedPR(t, prStorage, db, domain.PullRequest{ID: "load-pr-2", Title: "Two", AuthorID: "load-author"}, "load-busy")
+	testutil.SeedPR(t, prStorage, db, domain.PullRequest{ID: "load-pr-3", Title: "Done", AuthorID: "load-author", Status: domain.PRStatusMerged}, "load-idle")
+
+	counts, err := prStorage.GetOpenReviewCountsByTeam(ctx, teamName)
+	if err != nil {
+		t.Fatalf("failed to get open review counts: %v", err)
+	}
+	if counts["load-busy"] != 2 {
+		t.Fatalf("expected 2 open reviews for busy user, got %d", counts["load-busy"])
+	}
+	if c, ok := counts["load-idle"]; !ok || c != 0 {
+		t.Fatalf("expected idle user with 0 open reviews, got %d (present: %v)", c, ok)
+	}
+}
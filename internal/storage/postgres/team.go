@@ -5,7 +5,9 @@ import (
 	"database/sql"
 	"fmt"
 
+	"github.com/lib/pq"
 	"github.com/neizhmak/avito-review-service/internal/domain"
+	"github.com/neizhmak/avito-review-service/internal/storage"
 )
 
 type TeamStorage struct {
@@ -44,3 +46,285 @@ func (s *TeamStorage) GetByName(ctx context.Context, name string) (*domain.Team,
 
 	return &t, nil
 }
+
+// GetSettings retrieves the assignment settings of a team, falling back to defaults when none are stored.
+func (s *TeamStorage) GetSettings(ctx context.Context, teamName string) (*domain.TeamSettings, error) {
+	query := `
+		SELECT t.name, ts.reviewer_count, ts.min_reviewers, ts.reviewer_strategy, ts.allow_cross_team_fallback,
+		       ts.required_approvals, ts.block_on_changes_requested, ts.max_open_reviews, ts.queue_when_full,
+		       ts.pairing_window_days, ts.require_senior, ts.shadow_reviewer, ts.review_sla_hours,
+		       COALESCE(ts.sla_escalation, '')
+		FROM teams t
+		LEFT JOIN team_settings ts ON ts.team_name = t.name
+		WHERE t.name = $1
+	`
+
+	var (
+		name          string
+		reviewerCount sql.NullInt64
+		minReviewers  sql.NullInt64
+		strategy      sql.NullString
+		allowFallback sql.NullBool
+		approvals     sql.NullInt64
+		blockChanges  sql.NullBool
+		maxOpen       sql.NullInt64
+		queueWhenFull sql.NullBool
+		pairingWindow sql.NullInt64
+		requireSenior sql.NullBool
+		shadow        sql.NullBool
+		slaHours      sql.NullInt64
+		escalation    sql.NullString
+	)
+	err := s.db.QueryRowContext(ctx, query, teamName).Scan(
+		&name, &reviewerCount, &minReviewers, &strategy, &allowFallback, &approvals, &blockChanges,
+		&maxOpen, &queueWhenFull, &pairingWindow, &requireSenior, &shadow, &slaHours, &escalation,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: team", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get team settings: %w", err)
+	}
+
+	settings := domain.DefaultTeamSettings(name)
+	if reviewerCount.Valid {
+		settings.ReviewerCount = int(reviewerCount.Int64)
+		settings.MinReviewers = int(minReviewers.Int64)
+		settings.ReviewerStrategy = domain.ReviewerStrategy(strategy.String)
+		settings.AllowCrossTeamFallback = allowFallback.Bool
+		settings.RequiredApprovals = int(approvals.Int64)
+		settings.BlockOnChangesRequested = blockChanges.Bool
+		settings.MaxOpenReviews = int(maxOpen.Int64)
+		settings.QueueWhenFull = queueWhenFull.Bool
+		settings.PairingWindowDays = int(pairingWindow.Int64)
+		settings.RequireSenior = requireSenior.Bool
+		settings.ShadowReviewer = shadow.Bool
+		settings.ReviewSLAHours = int(slaHours.Int64)
+		settings.SLAEscalation = domain.SLAEscalation(escalation.String)
+	}
+
+	rows, err := s.db.QueryContext(ctx, "SELECT fallback_team FROM team_fallbacks WHERE team_name = $1 ORDER BY position", teamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query fallback teams: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	for rows.Next() {
+		var fallback string
+		if err := rows.Scan(&fallback); err != nil {
+			return nil, err
+		}
+		settings.FallbackTeams = append(settings.FallbackTeams, fallback)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sizeRows, err := s.db.QueryContext(ctx, "SELECT size, reviewer_count FROM team_size_reviewer_counts WHERE team_name = $1", teamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query size reviewer counts: %w", err)
+	}
+	defer func() {
+		_ = sizeRows.Close()
+	}()
+
+	for sizeRows.Next() {
+		var (
+			size  domain.PRSize
+			count int
+		)
+		if err := sizeRows.Scan(&size, &count); err != nil {
+			return nil, err
+		}
+		if settings.ReviewerCountBySize == nil {
+			settings.ReviewerCountBySize = make(map[domain.PRSize]int)
+		}
+		settings.ReviewerCountBySize[size] = count
+	}
+
+	return &settings, sizeRows.Err()
+}
+
+// SaveSettings creates or replaces the assignment settings of a team, including its fallback teams and
+// reviewer counts by size.
+// It issues several statements, so the executor should be a transaction.
+func (s *TeamStorage) SaveSettings(ctx context.Context, executor storage.QueryExecutor, settings domain.TeamSettings) error {
+	query := `
+		INSERT INTO team_settings (
+			team_name, reviewer_count, min_reviewers, reviewer_strategy, allow_cross_team_fallback,
+			required_approvals, block_on_changes_requested, max_open_reviews, queue_when_full, pairing_window_days,
+			require_senior, shadow_reviewer, review_sla_hours, sla_escalation
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, NULLIF($14, ''))
+		ON CONFLICT (team_name) DO UPDATE
+		SET reviewer_count = EXCLUDED.reviewer_count,
+		    min_reviewers = EXCLUDED.min_reviewers,
+		    reviewer_strategy = EXCLUDED.reviewer_strategy,
+		    allow_cross_team_fallback = EXCLUDED.allow_cross_team_fallback,
+		    required_approvals = EXCLUDED.required_approvals,
+		    block_on_changes_requested = EXCLUDED.block_on_changes_requested,
+		    max_open_reviews = EXCLUDED.max_open_reviews,
+		    queue_when_full = EXCLUDED.queue_when_full,
+		    pairing_window_days = EXCLUDED.pairing_window_days,
+		    require_senior = EXCLUDED.require_senior,
+		    shadow_reviewer = EXCLUDED.shadow_reviewer,
+		    review_sla_hours = EXCLUDED.review_sla_hours,
+		    sla_escalation = EXCLUDED.sla_escalation,
+		    updated_at = NOW()
+	`
+
+	_, err := executor.ExecContext(ctx, query,
+		settings.TeamName,
+		settings.ReviewerCount,
+		settings.MinReviewers,
+		settings.ReviewerStrategy,
+		settings.AllowCrossTeamFallback,
+		settings.RequiredApprovals,
+		settings.BlockOnChangesRequested,
+		settings.MaxOpenReviews,
+		settings.QueueWhenFull,
+		settings.PairingWindowDays,
+		settings.RequireSenior,
+		settings.ShadowReviewer,
+		settings.ReviewSLAHours,
+		string(settings.SLAEscalation),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save team settings: %w", err)
+	}
+
+	if _, err = executor.ExecContext(ctx, "DELETE FROM team_fallbacks WHERE team_name = $1", settings.TeamName); err != nil {
+		return fmt.Errorf("failed to clear fallback teams: %w", err)
+	}
+	for i, fallback := range settings.FallbackTeams {
+		query = "INSERT INTO team_fallbacks (team_name, fallback_team, position) VALUES ($1, $2, $3)"
+		if _, err = executor.ExecContext(ctx, query, settings.TeamName, fallback, i); err != nil {
+			return fmt.Errorf("failed to save fallback team %s: %w", fallback, err)
+		}
+	}
+
+	if _, err = executor.ExecContext(ctx, "DELETE FROM team_size_reviewer_counts WHERE team_name = $1", settings.TeamName); err != nil {
+		return fmt.Errorf("failed to clear size reviewer counts: %w", err)
+	}
+	for size, count := range settings.ReviewerCountBySize {
+		query = "INSERT INTO team_size_reviewer_counts (team_name, size, reviewer_count) VALUES ($1, $2, $3)"
+		if _, err = executor.ExecContext(ctx, query, settings.TeamName, size, count); err != nil {
+			return fmt.Errorf("failed to save reviewer count for size %s: %w", size, err)
+		}
+	}
+
+	return nil
+}
+
+// GetRoundRobinCursor returns the last reviewer picked by the team's round robin rotation, empty if there is none.
+func (s *TeamStorage) GetRoundRobinCursor(ctx context.Context, teamName string) (string, error) {
+	query := "SELECT round_robin_cursor FROM team_settings WHERE team_name = $1"
+
+	var cursor string
+	if err := s.db.QueryRowContext(ctx, query, teamName).Scan(&cursor); err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get round robin cursor: %w", err)
+	}
+	return cursor, nil
+}
+
+// SetRoundRobinCursor stores the last reviewer picked by the team's round robin rotation.
+func (s *TeamStorage) SetRoundRobinCursor(ctx context.Context, teamName, userID string) error {
+	query := "UPDATE team_settings SET round_robin_cursor = $2 WHERE team_name = $1"
+	if _, err := s.db.ExecContext(ctx, query, teamName, userID); err != nil {
+		return fmt.Errorf("failed to set round robin cursor: %w", err)
+	}
+	return nil
+}
+
+// SaveHoliday adds a holiday to the team calendar or renames an existing one.
+func (s *TeamStorage) SaveHoliday(ctx context.Context, holiday domain.Holiday) error {
+	query := `
+		INSERT INTO team_holidays (team_name, day, name)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (team_name, day) DO UPDATE SET name = EXCLUDED.name
+	`
+	if _, err := s.db.ExecContext(ctx, query, holiday.TeamName, holiday.Date, holiday.Name); err != nil {
+		return fmt.Errorf("failed to save holiday: %w", err)
+	}
+	return nil
+}
+
+// GetHolidays lists the holidays of a team in date order.
+func (s *TeamStorage) GetHolidays(ctx context.Context, teamName string) ([]domain.Holiday, error) {
+	query := "SELECT team_name, to_char(day, 'YYYY-MM-DD'), name FROM team_holidays WHERE team_name = $1 ORDER BY day"
+	rows, err := s.db.QueryContext(ctx, query, teamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query holidays: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	holidays := make([]domain.Holiday, 0)
+	for rows.Next() {
+		var h domain.Holiday
+		if err := rows.Scan(&h.TeamName, &h.Date, &h.Name); err != nil {
+			return nil, err
+		}
+		holidays = append(holidays, h)
+	}
+	return holidays, rows.Err()
+}
+
+// DeleteHoliday removes a holiday from the team calendar.
+func (s *TeamStorage) DeleteHoliday(ctx context.Context, teamName, date string) error {
+	res, err := s.db.ExecContext(ctx, "DELETE FROM team_holidays WHERE team_name = $1 AND day = $2", teamName, date)
+	if err != nil {
+		return fmt.Errorf("failed to delete holiday: %w", err)
+	}
+
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("%w: holiday", ErrNotFound)
+	}
+	return nil
+}
+
+// GetCodeOwnerRules retrieves all code owner rules in their configured order.
+func (s *TeamStorage) GetCodeOwnerRules(ctx context.Context) ([]domain.CodeOwnerRule, error) {
+	query := "SELECT pattern, user_ids, team_names FROM code_owner_rules ORDER BY position"
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query code owner rules: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	rules := make([]domain.CodeOwnerRule, 0)
+	for rows.Next() {
+		var r domain.CodeOwnerRule
+		if err := rows.Scan(&r.Pattern, pq.Array(&r.Users), pq.Array(&r.Teams)); err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+	return rules, rows.Err()
+}
+
+// ReplaceCodeOwnerRules replaces the whole set of code owner rules, keeping the given order.
+func (s *TeamStorage) ReplaceCodeOwnerRules(ctx context.Context, executor storage.QueryExecutor, rules []domain.CodeOwnerRule) error {
+	if _, err := executor.ExecContext(ctx, "DELETE FROM code_owner_rules"); err != nil {
+		return fmt.Errorf("failed to clear code owner rules: %w", err)
+	}
+
+	query := `
+		INSERT INTO code_owner_rules (position, pattern, user_ids, team_names)
+		VALUES ($1, $2, COALESCE($3::text[], '{}'), COALESCE($4::text[], '{}'))`
+	for i, r := range rules {
+		if _, err := executor.ExecContext(ctx, query, i, r.Pattern, pq.Array(r.Users), pq.Array(r.Teams)); err != nil {
+			return fmt.Errorf("failed to insert code owner rule: %w", err)
+		}
+	}
+	return nil
+}
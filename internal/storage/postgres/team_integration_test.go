@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"reflect"
 	"testing"
 
 	_ "github.com/lib/pq"
@@ -61,3 +62,90 @@ func TestTeamStorage_GetByName(t *testing.T) {
 		t.Fatalf("expected team name %s, got %s", teamName, got.Name)
 	}
 }
+
+func TestTeamStorage_Settings(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	ctx := context.Background()
+
+	teamStorage := NewTeamStorage(db)
+	teamName := "team-settings"
+	fallbackTeam := "team-settings-fallback"
+
+	testutil.CleanupTeamData(t, db, teamName)
+	testutil.CleanupTeamData(t, db, fallbackTeam)
+
+	for _, name := range []string{teamName, fallbackTeam} {
+		if err := teamStorage.Save(ctx, domain.Team{Name: name}); err != nil {
+			t.Fatalf("failed to save team %s: %v", name, err)
+		}
+	}
+
+	got, err := teamStorage.GetSettings(ctx, teamName)
+	if err != nil {
+		t.Fatalf("failed to get default settings: %v", err)
+	}
+	if !reflect.DeepEqual(*got, domain.DefaultTeamSettings(teamName)) {
+		t.Fatalf("expected default settings, got %+v", got)
+	}
+
+	want := domain.TeamSettings{
+		TeamName:               teamName,
+		ReviewerCount:          3,
+		MinReviewers:           1,
+		ReviewerStrategy:       domain.StrategyRoundRobin,
+		AllowCrossTeamFallback: true,
+		FallbackTeams:          []string{fallbackTeam},
+	}
+	if err = teamStorage.SaveSettings(ctx, db, want); err != nil {
+		t.Fatalf("failed to save settings: %v", err)
+	}
+
+	got, err = teamStorage.GetSettings(ctx, teamName)
+	if err != nil {
+		t.Fatalf("failed to get settings: %v", err)
+	}
+	if !reflect.DeepEqual(*got, want) {
+		t.Fatalf("want %+v, got %+v", want, got)
+	}
+
+	if _, err = teamStorage.GetSettings(ctx, "missing-team"); err == nil {
+		t.Fatalf("expected error for missing team")
+	}
+}
+
+func TestTeamStorage_CodeOwnerRules(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	ctx := context.Background()
+
+	teamStorage := NewTeamStorage(db)
+
+	previous, err := teamStorage.GetCodeOwnerRules(ctx)
+	if err != nil {
+		t.Fatalf("failed to get code owner rules: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = teamStorage.ReplaceCodeOwnerRules(context.Background(), db, previous)
+	})
+
+	rules := []domain.CodeOwnerRule{
+		{Pattern: "api/**", Users: []string{"u1", "u2"}, Teams: []string{"backend"}},
+		{Pattern: "web/**", Teams: []string{"frontend"}},
+		{Pattern: "docs/**", Users: []string{"u3"}},
+	}
+	if err = teamStorage.ReplaceCodeOwnerRules(ctx, db, rules); err != nil {
+		t.Fatalf("failed to replace code owner rules: %v", err)
+	}
+
+	got, err := teamStorage.GetCodeOwnerRules(ctx)
+	if err != nil {
+		t.Fatalf("failed to get code owner rules: %v", err)
+	}
+	want := []domain.CodeOwnerRule{
+		{Pattern: "api/**", Users: []string{"u1", "u2"}, Teams: []string{"backend"}},
+		{Pattern: "web/**", Users: []string{}, Teams: []string{"frontend"}},
+		{Pattern: "docs/**", Users: []string{"u3"}, Teams: []string{}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %+v, got %+v", want, got)
+	}
+}
@@ -4,11 +4,54 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
+	"github.com/lib/pq"
 	"github.com/neizhmak/avito-review-service/internal/domain"
 	"github.com/neizhmak/avito-review-service/internal/storage"
 )
 
+// userColumns lists the users columns read by scanUser, in order.
+const userColumns = "id, username, is_active, team_name, role, max_open_reviews, skills, " +
+	"time_zone, work_start_hour, work_end_hour, work_days"
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanUser reads a user selected with userColumns.
+func scanUser(row rowScanner) (domain.User, error) {
+	var (
+		u       domain.User
+		maxOpen sql.NullInt64
+		days    []int64
+	)
+	err := row.Scan(&u.ID, &u.Username, &u.IsActive, &u.TeamName, &u.Role, &maxOpen, pq.Array(&u.Skills),
+		&u.TimeZone, &u.WorkStartHour, &u.WorkEndHour, pq.Array(&days))
+	if err != nil {
+		return u, err
+	}
+	u.WorkDays = make([]int, 0, len(days))
+	for _, d := range days {
+		u.WorkDays = append(u.WorkDays, int(d))
+	}
+	if maxOpen.Valid {
+		limit := int(maxOpen.Int64)
+		u.MaxOpenReviews = &limit
+	}
+	return u, nil
+}
+
+// workDays converts weekday numbers for pq.Array.
+func workDays(days []int) []int64 {
+	result := make([]int64, 0, len(days))
+	for _, d := range days {
+		result = append(result, int64(d))
+	}
+	return result
+}
+
 type UserStorage struct {
 	db *sql.DB
 }
@@ -17,18 +60,54 @@ func NewUserStorage(db *sql.DB) *UserStorage {
 	return &UserStorage{db: db}
 }
 
-// Save saves a new user to the database.
+// Save saves a new user to the database. An existing user gets the given username, activity and team; their role,
+// review capacity, skills and schedule are only changed when set on user, and new users get the defaults for the
+// ones that are not.
 func (s *UserStorage) Save(ctx context.Context, user domain.User) error {
 	query := `
-		INSERT INTO users (id, username, is_active, team_name)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO users (
+			id, username, is_active, team_name, role, max_open_reviews, skills,
+			time_zone, work_start_hour, work_end_hour, work_days
+		)
+		VALUES (
+			$1, $2, $3, $4, COALESCE($5::text, $12), $6::int, COALESCE($7::text[], '{}'),
+			COALESCE($8::text, $13), COALESCE($9::int, $14), COALESCE($10::int, $15), COALESCE($11::int[], $16::int[])
+		)
 		ON CONFLICT (id) DO UPDATE
 		SET username = EXCLUDED.username,
 		    is_active = EXCLUDED.is_active,
-		    team_name = EXCLUDED.team_name
+		    team_name = EXCLUDED.team_name,
+		    role = COALESCE($5::text, users.role),
+		    max_open_reviews = COALESCE($6::int, users.max_open_reviews),
+		    skills = COALESCE($7::text[], users.skills),
+		    time_zone = COALESCE($8::text, users.time_zone),
+		    work_start_hour = COALESCE($9::int, users.work_start_hour),
+		    work_end_hour = COALESCE($10::int, users.work_end_hour),
+		    work_days = COALESCE($11::int[], users.work_days)
 	`
 
-	_, err := s.db.ExecContext(ctx, query, user.ID, user.Username, user.IsActive, user.TeamName)
+	// unset fields are passed as NULL
+	var role, timeZone *string
+	if user.Role != "" {
+		r := string(user.Role)
+		role = &r
+	}
+	if user.TimeZone != "" {
+		timeZone = &user.TimeZone
+	}
+	var startHour, endHour *int
+	if user.WorkStartHour != 0 || user.WorkEndHour != 0 {
+		startHour, endHour = &user.WorkStartHour, &user.WorkEndHour
+	}
+	var days []int64
+	if len(user.WorkDays) > 0 {
+		days = workDays(user.WorkDays)
+	}
+
+	_, err := s.db.ExecContext(ctx, query, user.ID, user.Username, user.IsActive, user.TeamName, role, user.MaxOpenReviews,
+		pq.Array(user.Skills), timeZone, startHour, endHour, pq.Array(days),
+		domain.DefaultUserRole, domain.DefaultTimeZone, domain.DefaultWorkStartHour, domain.DefaultWorkEndHour,
+		pq.Array(workDays(domain.DefaultWorkDays())))
 	if err != nil {
 		return fmt.Errorf("failed to insert user: %w", err)
 	}
@@ -36,9 +115,17 @@ func (s *UserStorage) Save(ctx context.Context, user domain.User) error {
 	return nil
 }
 
-// Gets a list of the team's active users.
+// Gets a list of the team's active users, leaving out those inside an unavailability period right now.
 func (s *UserStorage) GetActiveUsersByTeam(ctx context.Context, teamName string) ([]domain.User, error) {
-	query := "SELECT id, username, is_active, team_name FROM users WHERE team_name = $1 AND is_active = true"
+	query := `
+		SELECT ` + userColumns + `
+		FROM users u
+		WHERE team_name = $1 AND is_active = true
+		AND NOT EXISTS (
+			SELECT 1 FROM user_unavailability ua
+			WHERE ua.user_id = u.id AND ua.starts_at <= NOW() AND ua.ends_at > NOW()
+		)
+	`
 
 	rows, err := s.db.QueryContext(ctx, query, teamName)
 	if err != nil {
@@ -51,8 +138,8 @@ func (s *UserStorage) GetActiveUsersByTeam(ctx context.Context, teamName string)
 	users := make([]domain.User, 0)
 
 	for rows.Next() {
-		var u domain.User
-		if err := rows.Scan(&u.ID, &u.Username, &u.IsActive, &u.TeamName); err != nil {
+		u, err := scanUser(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan user: %w", err)
 		}
 		users = append(users, u)
@@ -67,12 +154,12 @@ func (s *UserStorage) GetActiveUsersByTeam(ctx context.Context, teamName string)
 
 // GetByID retrieves a user by their ID.
 func (s *UserStorage) GetByID(ctx context.Context, userID string) (*domain.User, error) {
-	query := "SELECT id, username, is_active, team_name FROM users WHERE id = $1"
+	query := "SELECT " + userColumns + " FROM users WHERE id = $1"
 
 	row := s.db.QueryRowContext(ctx, query, userID)
 
-	var u domain.User
-	if err := row.Scan(&u.ID, &u.Username, &u.IsActive, &u.TeamName); err != nil {
+	u, err := scanUser(row)
+	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("%w: user %s", ErrNotFound, userID)
 		}
@@ -84,7 +171,7 @@ func (s *UserStorage) GetByID(ctx context.Context, userID string) (*domain.User,
 
 // GetUsersByTeam retrieves all users belonging to a specific team.
 func (s *UserStorage) GetUsersByTeam(ctx context.Context, teamName string) ([]domain.User, error) {
-	query := "SELECT id, username, is_active, team_name FROM users WHERE team_name = $1"
+	query := "SELECT " + userColumns + " FROM users WHERE team_name = $1"
 	rows, err := s.db.QueryContext(ctx, query, teamName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query users: %w", err)
@@ -95,8 +182,8 @@ func (s *UserStorage) GetUsersByTeam(ctx context.Context, teamName string) ([]do
 
 	users := make([]domain.User, 0)
 	for rows.Next() {
-		var u domain.User
-		if err := rows.Scan(&u.ID, &u.Username, &u.IsActive, &u.TeamName); err != nil {
+		u, err := scanUser(rows)
+		if err != nil {
 			return nil, err
 		}
 		users = append(users, u)
@@ -105,9 +192,9 @@ func (s *UserStorage) GetUsersByTeam(ctx context.Context, teamName string) ([]do
 }
 
 // UpdateActivity updates the activity status of a user.
-func (s *UserStorage) UpdateActivity(ctx context.Context, userID string, isActive bool) error {
+func (s *UserStorage) UpdateActivity(ctx context.Context, executor storage.QueryExecutor, userID string, isActive bool) error {
 	query := "UPDATE users SET is_active = $1 WHERE id = $2"
-	res, err := s.db.ExecContext(ctx, query, isActive, userID)
+	res, err := executor.ExecContext(ctx, query, isActive, userID)
 	if err != nil {
 		return fmt.Errorf("failed to update user activity: %w", err)
 	}
@@ -119,6 +206,56 @@ func (s *UserStorage) UpdateActivity(ctx context.Context, userID string, isActiv
 	return nil
 }
 
+// SetMaxOpenReviews sets or, with nil, clears the personal review capacity of a user.
+func (s *UserStorage) SetMaxOpenReviews(ctx context.Context, userID string, limit *int) error {
+	res, err := s.db.ExecContext(ctx, "UPDATE users SET max_open_reviews = $1 WHERE id = $2", limit, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update user capacity: %w", err)
+	}
+
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("%w: user", ErrNotFound)
+	}
+	return nil
+}
+
+// SetRole changes the role of a user.
+func (s *UserStorage) SetRole(ctx context.Context, userID string, role domain.UserRole) error {
+	res, err := s.db.ExecContext(ctx, "UPDATE users SET role = $1 WHERE id = $2", role, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update user role: %w", err)
+	}
+
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("%w: user", ErrNotFound)
+	}
+	return nil
+}
+
+// UpdateProfile changes the username, skills and working schedule of a user.
+func (s *UserStorage) UpdateProfile(ctx context.Context, user domain.User) error {
+	query := `
+		UPDATE users
+		SET username = $1, skills = COALESCE($2::text[], '{}'),
+		    time_zone = $3, work_start_hour = $4, work_end_hour = $5, work_days = $6
+		WHERE id = $7
+	`
+	user.ApplyScheduleDefaults()
+	res, err := s.db.ExecContext(ctx, query, user.Username, pq.Array(user.Skills),
+		user.TimeZone, user.WorkStartHour, user.WorkEndHour, pq.Array(workDays(user.WorkDays)), user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("%w: user", ErrNotFound)
+	}
+	return nil
+}
+
 // MassDeactivate sets is_active to false for all users in the specified team.
 func (s *UserStorage) MassDeactivate(ctx context.Context, executor storage.QueryExecutor, teamName string) error {
 	query := "UPDATE users SET is_active = false WHERE team_name = $1"
@@ -128,3 +265,167 @@ func (s *UserStorage) MassDeactivate(ctx context.Context, executor storage.Query
 	}
 	return nil
 }
+
+// IsUnavailable reports whether the user is inside an unavailability period at the given time.
+func (s *UserStorage) IsUnavailable(ctx context.Context, userID string, at time.Time) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM user_unavailability
+			WHERE user_id = $1 AND starts_at <= $2 AND ends_at > $2
+		)
+	`
+	var unavailable bool
+	if err := s.db.QueryRowContext(ctx, query, userID, at).Scan(&unavailable); err != nil {
+		return false, fmt.Errorf("failed to check user availability: %w", err)
+	}
+	return unavailable, nil
+}
+
+// AddUnavailability stores a new unavailability period and returns its id.
+func (s *UserStorage) AddUnavailability(ctx context.Context, period domain.Unavailability) (int64, error) {
+	query := `
+		INSERT INTO user_unavailability (user_id, starts_at, ends_at, reason)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+	var id int64
+	err := s.db.QueryRowContext(ctx, query, period.UserID, period.StartsAt, period.EndsAt, period.Reason).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert unavailability: %w", err)
+	}
+	return id, nil
+}
+
+// GetUnavailability retrieves the unavailability periods of a user that have not ended yet, ordered by start.
+func (s *UserStorage) GetUnavailability(ctx context.Context, userID string) ([]domain.Unavailability, error) {
+	query := `
+		SELECT id, user_id, starts_at, ends_at, reason
+		FROM user_unavailability
+		WHERE user_id = $1 AND ends_at > NOW()
+		ORDER BY starts_at, id
+	`
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query unavailability: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	periods := make([]domain.Unavailability, 0)
+	for rows.Next() {
+		var p domain.Unavailability
+		if err := rows.Scan(&p.ID, &p.UserID, &p.StartsAt, &p.EndsAt, &p.Reason); err != nil {
+			return nil, err
+		}
+		periods = append(periods, p)
+	}
+	return periods, rows.Err()
+}
+
+// DeleteUnavailability removes an unavailability period of a user.
+func (s *UserStorage) DeleteUnavailability(ctx context.Context, userID string, id int64) error {
+	res, err := s.db.ExecContext(ctx, "DELETE FROM user_unavailability WHERE id = $1 AND user_id = $2", id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete unavailability: %w", err)
+	}
+
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("%w: unavailability", ErrNotFound)
+	}
+	return nil
+}
+
+// AddExclusionRule stores a new reviewer exclusion rule and returns its id.
+func (s *UserStorage) AddExclusionRule(ctx context.Context, rule domain.ExclusionRule) (int64, error) {
+	query := `
+		INSERT INTO reviewer_exclusions (reviewer_id, author_id, mutual, until, reason)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+	var id int64
+	err := s.db.QueryRowContext(ctx, query, rule.ReviewerID, rule.AuthorID, rule.Mutual, rule.Until, rule.Reason).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert exclusion rule: %w", err)
+	}
+	return id, nil
+}
+
+// GetExclusionRules retrieves the exclusion rules still in force, ordered by id.
+// If userID is set, only rules naming that user on either side are returned.
+func (s *UserStorage) GetExclusionRules(ctx context.Context, userID string) ([]domain.ExclusionRule, error) {
+	query := `
+		SELECT id, reviewer_id, author_id, mutual, until, reason
+		FROM reviewer_exclusions
+		WHERE (until IS NULL OR until > NOW())
+		AND ($1 = '' OR reviewer_id = $1 OR author_id = $1)
+		ORDER BY id
+	`
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query exclusion rules: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	rules := make([]domain.ExclusionRule, 0)
+	for rows.Next() {
+		var (
+			r     domain.ExclusionRule
+			until sql.NullTime
+		)
+		if err := rows.Scan(&r.ID, &r.ReviewerID, &r.AuthorID, &r.Mutual, &until, &r.Reason); err != nil {
+			return nil, err
+		}
+		if until.Valid {
+			r.Until = &until.Time
+		}
+		rules = append(rules, r)
+	}
+	return rules, rows.Err()
+}
+
+// DeleteExclusionRule removes an exclusion rule.
+func (s *UserStorage) DeleteExclusionRule(ctx context.Context, id int64) error {
+	res, err := s.db.ExecContext(ctx, "DELETE FROM reviewer_exclusions WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete exclusion rule: %w", err)
+	}
+
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("%w: exclusion rule", ErrNotFound)
+	}
+	return nil
+}
+
+// GetBlockedReviewers returns the users that exclusion rules in force at the given time forbid from reviewing
+// the author's pull requests.
+func (s *UserStorage) GetBlockedReviewers(ctx context.Context, authorID string, at time.Time) ([]string, error) {
+	query := `
+		SELECT reviewer_id FROM reviewer_exclusions
+		WHERE author_id = $1 AND (until IS NULL OR until > $2)
+		UNION
+		SELECT author_id FROM reviewer_exclusions
+		WHERE reviewer_id = $1 AND mutual AND (until IS NULL OR until > $2)
+	`
+	rows, err := s.db.QueryContext(ctx, query, authorID, at)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query blocked reviewers: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -36,7 +36,7 @@ func TestUserStorage_UpdateAndMassDeactivate(t *testing.T) {
 		}
 	}
 
-	if err = userStorage.UpdateActivity(ctx, userActive, false); err != nil {
+	if err = userStorage.UpdateActivity(ctx, db, userActive, false); err != nil {
 		t.Fatalf("failed to update activity: %v", err)
 	}
 
@@ -53,6 +53,49 @@ func TestUserStorage_Save(t *testing.T) {
 	if savedName != user.Username {
 		t.Errorf("want %s, got %s", user.Username, savedName)
 	}
+
+	saved, err := userStorage.GetByID(ctx, userID)
+	if err != nil {
+		t.Fatalf("failed to get user: %v", err)
+	}
+	if saved.Role != domain.DefaultUserRole || saved.TimeZone != domain.DefaultTimeZone || saved.WorkStartHour != domain.DefaultWorkStartHour {
+		t.Fatalf("expected defaults for a new user, got %+v", saved)
+	}
+
+	// Test re-saving keeps the fields that are not set
+	limit := 2
+	profiled := domain.User{
+		ID:             userID,
+		Username:       "TestUser",
+		IsActive:       true,
+		TeamName:       teamName,
+		Role:           domain.RoleSenior,
+		MaxOpenReviews: &limit,
+		Skills:         []string{"go"},
+		TimeZone:       "Europe/Moscow",
+		WorkStartHour:  10,
+		WorkEndHour:    19,
+		WorkDays:       []int{1, 2, 3},
+	}
+	if err = userStorage.Save(ctx, profiled); err != nil {
+		t.Fatalf("unexpected error saving user: %v", err)
+	}
+	if err = userStorage.Save(ctx, domain.User{ID: userID, Username: "Renamed", IsActive: false, TeamName: teamName}); err != nil {
+		t.Fatalf("unexpected error re-saving user: %v", err)
+	}
+
+	saved, err = userStorage.GetByID(ctx, userID)
+	if err != nil {
+		t.Fatalf("failed to get user: %v", err)
+	}
+	if saved.Username != "Renamed" || saved.IsActive {
+		t.Fatalf("expected username and activity updated, got %+v", saved)
+	}
+	if saved.Role != domain.RoleSenior || saved.MaxOpenReviews == nil || *saved.MaxOpenReviews != limit ||
+		len(saved.Skills) != 1 || saved.TimeZone != "Europe/Moscow" || saved.WorkStartHour != 10 ||
+		saved.WorkEndHour != 19 || len(saved.WorkDays) != 3 {
+		t.Fatalf("expected role, capacity, skills and schedule kept, got %+v", saved)
+	}
 }
 
 func TestGetActiveUsersByTeam(t *testing.T) {
@@ -0,0 +1,232 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/neizhmak/avito-review-service/internal/domain"
+	"github.com/neizhmak/avito-review-service/internal/storage"
+)
+
+// deliveryColumns lists the webhook_deliveries columns read by scanDelivery, in order.
+const deliveryColumns = "id, webhook_id, event_type, payload, status, attempts, next_attempt_at, last_status_code, " +
+	"last_error, created_at, delivered_at"
+
+// scanDelivery reads a delivery selected with deliveryColumns.
+func scanDelivery(row rowScanner) (domain.WebhookDelivery, error) {
+	var (
+		d           domain.WebhookDelivery
+		payload     []byte
+		deliveredAt sql.NullTime
+	)
+	err := row.Scan(&d.ID, &d.WebhookID, &d.EventType, &payload, &d.Status, &d.Attempts, &d.NextAttemptAt,
+		&d.LastStatusCode, &d.LastError, &d.CreatedAt, &deliveredAt)
+	if err != nil {
+		return d, err
+	}
+	d.Payload = payload
+	if deliveredAt.Valid {
+		d.DeliveredAt = &deliveredAt.Time
+	}
+	return d, nil
+}
+
+// eventTypeStrings converts event types for pq.Array.
+func eventTypeStrings(types []domain.WebhookEventType) []string {
+	result := make([]string, 0, len(types))
+	for _, t := range types {
+		result = append(result, string(t))
+	}
+	return result
+}
+
+type WebhookStorage struct {
+	db *sql.DB
+}
+
+func NewWebhookStorage(db *sql.DB) *WebhookStorage {
+	return &WebhookStorage{db: db}
+}
+
+// Create stores a new webhook subscription and returns it with its id and creation time set.
+func (s *WebhookStorage) Create(ctx context.Context, webhook domain.Webhook) (*domain.Webhook, error) {
+	query := `
+		INSERT INTO webhook_subscriptions (url, secret, event_types)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`
+	err := s.db.QueryRowContext(ctx, query, webhook.URL, webhook.Secret, pq.Array(eventTypeStrings(webhook.EventTypes))).
+		Scan(&webhook.ID, &webhook.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert webhook: %w", err)
+	}
+	return &webhook, nil
+}
+
+// GetByID retrieves a webhook subscription together with its secret.
+func (s *WebhookStorage) GetByID(ctx context.Context, id int64) (*domain.Webhook, error) {
+	query := "SELECT id, url, secret, event_types, created_at FROM webhook_subscriptions WHERE id = $1"
+
+	var (
+		w     domain.Webhook
+		types []string
+	)
+	err := s.db.QueryRowContext(ctx, query, id).Scan(&w.ID, &w.URL, &w.Secret, pq.Array(&types), &w.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: webhook", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	for _, t := range types {
+		w.EventTypes = append(w.EventTypes, domain.WebhookEventType(t))
+	}
+	return &w, nil
+}
+
+// List retrieves all webhook subscriptions without their secrets.
+func (s *WebhookStorage) List(ctx context.Context) ([]domain.Webhook, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT id, url, event_types, created_at FROM webhook_subscriptions ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhooks: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	webhooks := make([]domain.Webhook, 0)
+	for rows.Next() {
+		var (
+			w     domain.Webhook
+			types []string
+		)
+		if err := rows.Scan(&w.ID, &w.URL, pq.Array(&types), &w.CreatedAt); err != nil {
+			return nil, err
+		}
+		for _, t := range types {
+			w.EventTypes = append(w.EventTypes, domain.WebhookEventType(t))
+		}
+		webhooks = append(webhooks, w)
+	}
+	return webhooks, rows.Err()
+}
+
+// Delete removes a webhook subscription along with its delivery log.
+func (s *WebhookStorage) Delete(ctx context.Context, id int64) error {
+	res, err := s.db.ExecContext(ctx, "DELETE FROM webhook_subscriptions WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("%w: webhook", ErrNotFound)
+	}
+	return nil
+}
+
+// EnqueueDeliveries queues the payload for every webhook subscribed to the event type.
+func (s *WebhookStorage) EnqueueDeliveries(
+	ctx context.Context,
+	executor storage.QueryExecutor,
+	eventType domain.WebhookEventType,
+	payload []byte,
+) error {
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event_type, payload)
+		SELECT id, $1, $2 FROM webhook_subscriptions WHERE $1 = ANY (event_types)
+	`
+	if _, err := executor.ExecContext(ctx, query, eventType, payload); err != nil {
+		return fmt.Errorf("failed to enqueue webhook deliveries: %w", err)
+	}
+	return nil
+}
+
+// ClaimDueDeliveries returns up to limit pending deliveries whose next attempt is due at the given moment and
+// postpones them by lease, so that concurrent workers do not send them twice.
+func (s *WebhookStorage) ClaimDueDeliveries(ctx context.Context, at time.Time, limit int, lease time.Duration) ([]domain.WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = $3
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'PENDING' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at, id
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + deliveryColumns
+	rows, err := s.db.QueryContext(ctx, query, at, limit, at.Add(lease))
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	deliveries := make([]domain.WebhookDelivery, 0)
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+// UpdateDelivery stores the outcome of a delivery attempt.
+func (s *WebhookStorage) UpdateDelivery(ctx context.Context, delivery domain.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = $2, next_attempt_at = $3, last_status_code = $4, last_error = $5, delivered_at = $6
+		WHERE id = $7
+	`
+	_, err := s.db.ExecContext(ctx, query, delivery.Status, delivery.Attempts, delivery.NextAttemptAt,
+		delivery.LastStatusCode, delivery.LastError, delivery.DeliveredAt, delivery.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// GetDeliveries retrieves the latest deliveries of a webhook, newest first.
+func (s *WebhookStorage) GetDeliveries(ctx context.Context, webhookID int64, limit int) ([]domain.WebhookDelivery, error) {
+	query := "SELECT " + deliveryColumns + " FROM webhook_deliveries WHERE webhook_id = $1 ORDER BY id DESC LIMIT $2"
+	rows, err := s.db.QueryContext(ctx, query, webhookID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook deliveries: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	deliveries := make([]domain.WebhookDelivery, 0)
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+// Redeliver queues a copy of an earlier delivery for immediate sending and returns the new delivery.
+func (s *WebhookStorage) Redeliver(ctx context.Context, deliveryID int64) (*domain.WebhookDelivery, error) {
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event_type, payload)
+		SELECT webhook_id, event_type, payload FROM webhook_deliveries WHERE id = $1
+		RETURNING ` + deliveryColumns
+	d, err := scanDelivery(s.db.QueryRowContext(ctx, query, deliveryID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: webhook delivery", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to redeliver webhook delivery: %w", err)
+	}
+	return &d, nil
+}
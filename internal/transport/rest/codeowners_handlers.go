@@ -0,0 +1,51 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/neizhmak/avito-review-service/internal/domain"
+)
+
+type setCodeOwnersRequest struct {
+	Rules []domain.CodeOwnerRule `json:"rules"`
+}
+
+// getCodeOwners handles the HTTP request to list code owner rules.
+func (h *Handler) getCodeOwners(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.service.GetCodeOwnerRules(r.Context())
+	if err != nil {
+		status, code, msg := mapError(err)
+		respondError(w, status, code, msg)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"rules": rules,
+	})
+}
+
+// setCodeOwners handles the HTTP request to replace all code owner rules.
+func (h *Handler) setCodeOwners(w http.ResponseWriter, r *http.Request) {
+	var req setCodeOwnersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "ERROR", "invalid json")
+		return
+	}
+
+	if req.Rules == nil {
+		respondError(w, http.StatusBadRequest, "ERROR", "rules are required")
+		return
+	}
+
+	rules, err := h.service.SetCodeOwnerRules(r.Context(), req.Rules)
+	if err != nil {
+		status, code, msg := mapError(err)
+		respondError(w, status, code, msg)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"rules": rules,
+	})
+}
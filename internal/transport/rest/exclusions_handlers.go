@@ -0,0 +1,81 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/neizhmak/avito-review-service/internal/domain"
+)
+
+type addExclusionRuleRequest struct {
+	ReviewerID string     `json:"reviewer_id"`
+	AuthorID   string     `json:"author_id"`
+	Mutual     bool       `json:"mutual"`
+	Until      *time.Time `json:"until"`
+	Reason     string     `json:"reason"`
+}
+
+// getExclusionRules handles the HTTP request to list exclusion rules, optionally for one user.
+func (h *Handler) getExclusionRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.service.GetExclusionRules(r.Context(), r.URL.Query().Get("user_id"))
+	if err != nil {
+		status, code, msg := mapError(err)
+		respondError(w, status, code, msg)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"rules": rules,
+	})
+}
+
+// addExclusionRule handles the HTTP request to forbid a user from reviewing another user's pull requests.
+func (h *Handler) addExclusionRule(w http.ResponseWriter, r *http.Request) {
+	var req addExclusionRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "ERROR", "invalid json")
+		return
+	}
+
+	if strings.TrimSpace(req.ReviewerID) == "" || strings.TrimSpace(req.AuthorID) == "" {
+		respondError(w, http.StatusBadRequest, "ERROR", "reviewer_id and author_id are required")
+		return
+	}
+
+	rule, err := h.service.AddExclusionRule(r.Context(), domain.ExclusionRule{
+		ReviewerID: req.ReviewerID,
+		AuthorID:   req.AuthorID,
+		Mutual:     req.Mutual,
+		Until:      req.Until,
+		Reason:     req.Reason,
+	})
+	if err != nil {
+		status, code, msg := mapError(err)
+		respondError(w, status, code, msg)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, map[string]interface{}{
+		"rule": rule,
+	})
+}
+
+// deleteExclusionRule handles the HTTP request to remove an exclusion rule.
+func (h *Handler) deleteExclusionRule(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "ERROR", "numeric id is required")
+		return
+	}
+
+	if err = h.service.DeleteExclusionRule(r.Context(), id); err != nil {
+		status, code, msg := mapError(err)
+		respondError(w, status, code, msg)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -27,20 +28,67 @@ func (h *Handler) InitRouter() *chi.Mux {
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.SetHeader("Content-Type", "application/json"))
+	r.Use(withActor)
 
 	r.Post("/team/add", h.createTeam)
 	r.Post("/team/deactivate", h.deactivateTeam)
 	r.Get("/team/get", h.getTeam)
+	r.Get("/team/settings", h.getTeamSettings)
+	r.Put("/team/settings", h.updateTeamSettings)
+	r.Get("/team/holidays", h.getHolidays)
+	r.Post("/team/holidays", h.addHoliday)
+	r.Delete("/team/holidays", h.deleteHoliday)
 	r.Post("/users/setIsActive", h.setUserActive)
+	r.Post("/users/setCapacity", h.setUserCapacity)
+	r.Post("/users/setRole", h.setUserRole)
+	r.Post("/users/update", h.updateUser)
 	r.Get("/users/getReview", h.getUserReviews)
+	r.Get("/users/availability", h.getUnavailability)
+	r.Post("/users/availability", h.addUnavailability)
+	r.Delete("/users/availability", h.deleteUnavailability)
 	r.Post("/pullRequest/create", h.createPR)
 	r.Post("/pullRequest/merge", h.mergePR)
 	r.Post("/pullRequest/reassign", h.reassignReviewer)
+	r.Post("/pullRequest/addReviewer", h.addReviewer)
+	r.Post("/pullRequest/removeReviewer", h.removeReviewer)
+	r.Post("/pullRequest/review", h.reviewPR)
+	r.Post("/pullRequest/decline", h.declineReview)
+	r.Get("/pullRequest/declines", h.getDeclines)
+	r.Post("/pullRequest/ready", h.readyPR)
+	r.Post("/pullRequest/close", h.closePR)
+	r.Post("/pullRequest/reopen", h.reopenPR)
+	r.Get("/pullRequest/unassigned", h.getUnassignedPRs)
+	r.Get("/pullRequest/history", h.getPRHistory)
+	r.Get("/codeOwners", h.getCodeOwners)
+	r.Put("/codeOwners", h.setCodeOwners)
+	r.Get("/exclusionRules", h.getExclusionRules)
+	r.Post("/exclusionRules", h.addExclusionRule)
+	r.Delete("/exclusionRules", h.deleteExclusionRule)
+	r.Get("/webhooks", h.getWebhooks)
+	r.Post("/webhooks", h.createWebhook)
+	r.Delete("/webhooks", h.deleteWebhook)
+	r.Get("/webhooks/deliveries", h.getWebhookDeliveries)
+	r.Post("/webhooks/redeliver", h.redeliverWebhook)
+	r.Get("/reviews/overdue", h.getOverdueReviews)
 	r.Get("/health/stats", h.getStats)
+	r.Get("/stats/pairings", h.getPairings)
 
 	return r
 }
 
+// actorHeader names the caller to whom changes made by a request are attributed in pull request history.
+const actorHeader = "X-Actor"
+
+// withActor passes the X-Actor header of the request on to the service.
+func withActor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if actor := strings.TrimSpace(r.Header.Get(actorHeader)); actor != "" {
+			r = r.WithContext(service.WithActor(r.Context(), actor))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 // respondJSON writes a JSON response with the given status code and payload.
 func respondJSON(w http.ResponseWriter, status int, payload interface{}) {
 	w.WriteHeader(status)
@@ -74,9 +122,12 @@ func mapError(err error) (int, string, string) {
 		switch svcErr.Code {
 		case service.ErrCodeNotFound:
 			return http.StatusNotFound, svcErr.Code, svcErr.Msg
-		case service.ErrCodeTeamExists:
+		case service.ErrCodeTeamExists, service.ErrCodeUnknownStrategy, service.ErrCodeInvalidSettings,
+			service.ErrCodeInvalidRule, service.ErrCodeInvalidPeriod, service.ErrCodeInvalidWebhook:
 			return http.StatusBadRequest, svcErr.Code, svcErr.Msg
-		case service.ErrCodePRExists, service.ErrCodePRMerged, service.ErrCodeNotAssigned, service.ErrCodeNoCandidate:
+		case service.ErrCodePRExists, service.ErrCodePRMerged, service.ErrCodeNotAssigned, service.ErrCodeNoCandidate,
+			service.ErrCodeMergeBlocked, service.ErrCodeInvalidStatus, service.ErrCodeCapacityExceeded,
+			service.ErrCodeInvalidReviewer, service.ErrCodeAlreadyAssigned:
 			return http.StatusConflict, svcErr.Code, svcErr.Msg
 		default:
 			slog.Error("unexpected service error", "error", err)
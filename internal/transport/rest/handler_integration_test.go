@@ -51,7 +51,9 @@ func TestHandler_Flow(t *testing.T) {
 	}
 
 	var createBody struct {
-		PR domain.PullRequest `json:"pr"`
+		PR struct {
+			Reviewers []string `json:"assigned_reviewers"`
+		} `json:"pr"`
 	}
 	if err = json.NewDecoder(resp.Body).Decode(&createBody); err != nil {
 		t.Fatalf("failed to decode createPR response: %v", err)
@@ -29,12 +29,72 @@ func TestMapError(t *testing.T) {
 			wantStatus: http.StatusBadRequest,
 			wantCode:   service.ErrCodeTeamExists,
 		},
+		{
+			name:       "unknown strategy",
+			err:        &service.ServiceError{Code: service.ErrCodeUnknownStrategy, Msg: "bad"},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   service.ErrCodeUnknownStrategy,
+		},
+		{
+			name:       "invalid settings",
+			err:        &service.ServiceError{Code: service.ErrCodeInvalidSettings, Msg: "bad"},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   service.ErrCodeInvalidSettings,
+		},
+		{
+			name:       "invalid rule",
+			err:        &service.ServiceError{Code: service.ErrCodeInvalidRule, Msg: "bad"},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   service.ErrCodeInvalidRule,
+		},
 		{
 			name:       "conflict codes",
 			err:        &service.ServiceError{Code: service.ErrCodePRMerged, Msg: "merged"},
 			wantStatus: http.StatusConflict,
 			wantCode:   service.ErrCodePRMerged,
 		},
+		{
+			name:       "merge blocked",
+			err:        &service.ServiceError{Code: service.ErrCodeMergeBlocked, Msg: "blocked"},
+			wantStatus: http.StatusConflict,
+			wantCode:   service.ErrCodeMergeBlocked,
+		},
+		{
+			name:       "invalid status",
+			err:        &service.ServiceError{Code: service.ErrCodeInvalidStatus, Msg: "draft"},
+			wantStatus: http.StatusConflict,
+			wantCode:   service.ErrCodeInvalidStatus,
+		},
+		{
+			name:       "invalid period",
+			err:        &service.ServiceError{Code: service.ErrCodeInvalidPeriod, Msg: "bad"},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   service.ErrCodeInvalidPeriod,
+		},
+		{
+			name:       "invalid webhook",
+			err:        &service.ServiceError{Code: service.ErrCodeInvalidWebhook, Msg: "bad"},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   service.ErrCodeInvalidWebhook,
+		},
+		{
+			name:       "capacity exceeded",
+			err:        &service.ServiceError{Code: service.ErrCodeCapacityExceeded, Msg: "full"},
+			wantStatus: http.StatusConflict,
+			wantCode:   service.ErrCodeCapacityExceeded,
+		},
+		{
+			name:       "invalid reviewer",
+			err:        &service.ServiceError{Code: service.ErrCodeInvalidReviewer, Msg: "inactive"},
+			wantStatus: http.StatusConflict,
+			wantCode:   service.ErrCodeInvalidReviewer,
+		},
+		{
+			name:       "already assigned",
+			err:        &service.ServiceError{Code: service.ErrCodeAlreadyAssigned, Msg: "dup"},
+			wantStatus: http.StatusConflict,
+			wantCode:   service.ErrCodeAlreadyAssigned,
+		},
 		{
 			name:       "unknown service code",
 			err:        &service.ServiceError{Code: "CUSTOM", Msg: "oops"},
@@ -87,11 +147,75 @@ func TestHandler_ValidationErrors(t *testing.T) {
 			body:       `{}`,
 			wantStatus: http.StatusBadRequest,
 		},
+		{
+			name:       "addReviewer missing reviewer",
+			handler:    h.addReviewer,
+			body:       `{"pull_request_id":"pr"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "removeReviewer invalid json",
+			handler:    h.removeReviewer,
+			body:       `{`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "review missing ids",
+			handler:    h.reviewPR,
+			body:       `{"state":"APPROVED"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "review invalid state",
+			handler:    h.reviewPR,
+			body:       `{"pull_request_id":"pr","reviewer_id":"u","state":"LGTM"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "decline invalid reason",
+			handler:    h.declineReview,
+			body:       `{"pull_request_id":"pr","reviewer_id":"u","reason":"BORED"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "getDeclines missing query",
+			handler:    h.getDeclines,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "close missing id",
+			handler:    h.closePR,
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+		},
 		{
 			name:       "getTeam missing query",
 			handler:    h.getTeam,
 			wantStatus: http.StatusBadRequest,
 		},
+		{
+			name:       "getTeamSettings missing query",
+			handler:    h.getTeamSettings,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "updateTeamSettings missing team",
+			handler:    h.updateTeamSettings,
+			body:       `{"reviewer_count":3}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "setCodeOwners missing rules",
+			handler:    h.setCodeOwners,
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "getPairings bad days",
+			handler:    h.getPairings,
+			query:      "team_name=t&days=-1",
+			wantStatus: http.StatusBadRequest,
+		},
 		{
 			name:       "getUserReviews missing query",
 			handler:    h.getUserReviews,
@@ -103,6 +227,117 @@ func TestHandler_ValidationErrors(t *testing.T) {
 			body:       `{}`,
 			wantStatus: http.StatusBadRequest,
 		},
+		{
+			name:       "setUserCapacity missing id",
+			handler:    h.setUserCapacity,
+			body:       `{"max_open_reviews":3}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "setUserRole missing id",
+			handler:    h.setUserRole,
+			body:       `{"role":"senior"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "setUserRole unknown role",
+			handler:    h.setUserRole,
+			body:       `{"user_id":"u","role":"principal"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "createPR negative diff stats",
+			handler:    h.createPR,
+			body:       `{"pull_request_id":"p","pull_request_name":"P","author_id":"u","diff_stats":{"lines_added":-1}}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "updateUser missing id",
+			handler:    h.updateUser,
+			body:       `{"skills":["sql"]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "updateUser blank username",
+			handler:    h.updateUser,
+			body:       `{"user_id":"u","username":"  "}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "createTeam unknown member role",
+			handler:    h.createTeam,
+			body:       `{"team_name":"t","members":[{"user_id":"u","username":"U","role":"intern"}]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "addUnavailability bad time",
+			handler:    h.addUnavailability,
+			body:       `{"user_id":"u","starts_at":"tomorrow"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "addExclusionRule missing author",
+			handler:    h.addExclusionRule,
+			body:       `{"reviewer_id":"u"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "deleteExclusionRule bad id",
+			handler:    h.deleteExclusionRule,
+			query:      "id=abc",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "deleteUnavailability bad id",
+			handler:    h.deleteUnavailability,
+			query:      "user_id=u&id=abc",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "getPRHistory missing query",
+			handler:    h.getPRHistory,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "createWebhook missing event types",
+			handler:    h.createWebhook,
+			body:       `{"url":"https://bot.example.com/hook"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "deleteWebhook bad id",
+			handler:    h.deleteWebhook,
+			query:      "id=abc",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "getWebhookDeliveries missing id",
+			handler:    h.getWebhookDeliveries,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "redeliverWebhook missing id",
+			handler:    h.redeliverWebhook,
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "getHolidays missing query",
+			handler:    h.getHolidays,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "addHoliday bad date",
+			handler:    h.addHoliday,
+			body:       `{"team_name":"backend","date":"31.12.2025"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "deleteHoliday bad date",
+			handler:    h.deleteHoliday,
+			query:      "team_name=backend&date=tomorrow",
+			wantStatus: http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
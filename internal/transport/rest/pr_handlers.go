@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"strings"
@@ -9,18 +10,47 @@ import (
 )
 
 type createPRRequest struct {
-	ID       string `json:"pull_request_id"`
-	Title    string `json:"pull_request_name"`
-	AuthorID string `json:"author_id"`
+	ID           string            `json:"pull_request_id"`
+	Title        string            `json:"pull_request_name"`
+	AuthorID     string            `json:"author_id"`
+	ChangedFiles []string          `json:"changed_files,omitempty"`
+	Draft        bool              `json:"draft"`
+	Requested    []string          `json:"requested_reviewers,omitempty"`
+	Excluded     []string          `json:"excluded_reviewers,omitempty"`
+	Labels       []string          `json:"labels,omitempty"`
+	DiffStats    *domain.DiffStats `json:"diff_stats,omitempty"`
 }
 
 type mergePRRequest struct {
+	PRID  string `json:"pull_request_id"`
+	Force bool   `json:"force"`
+}
+
+type prStatusRequest struct {
 	PRID string `json:"pull_request_id"`
 }
 
+type reviewPRRequest struct {
+	PRID       string             `json:"pull_request_id"`
+	ReviewerID string             `json:"reviewer_id"`
+	State      domain.ReviewState `json:"state"`
+}
+
+type declinePRRequest struct {
+	PRID       string               `json:"pull_request_id"`
+	ReviewerID string               `json:"reviewer_id"`
+	Reason     domain.DeclineReason `json:"reason"`
+}
+
 type reassignPRRequest struct {
 	PRID      string `json:"pull_request_id"`
 	OldUserID string `json:"old_user_id"`
+	NewUserID string `json:"new_user_id"`
+}
+
+type prReviewerRequest struct {
+	PRID       string `json:"pull_request_id"`
+	ReviewerID string `json:"reviewer_id"`
 }
 
 // createPR handles the HTTP request to create a new pull request.
@@ -35,12 +65,26 @@ func (h *Handler) createPR(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusBadRequest, "ERROR", "pull_request_id, pull_request_name and author_id are required")
 		return
 	}
+	if d := req.DiffStats; d != nil && (d.LinesAdded < 0 || d.LinesRemoved < 0 || d.FilesChanged < 0) {
+		respondError(w, http.StatusBadRequest, "ERROR", "diff_stats must not be negative")
+		return
+	}
 
-	createdPR, err := h.service.Create(r.Context(), domain.PullRequest{
-		ID:       req.ID,
-		Title:    req.Title,
-		AuthorID: req.AuthorID,
-	})
+	pr := domain.PullRequest{
+		ID:                 req.ID,
+		Title:              req.Title,
+		AuthorID:           req.AuthorID,
+		ChangedFiles:       req.ChangedFiles,
+		RequestedReviewers: req.Requested,
+		ExcludedReviewers:  req.Excluded,
+		Labels:             req.Labels,
+		DiffStats:          req.DiffStats,
+	}
+	if req.Draft {
+		pr.Status = domain.PRStatusDraft
+	}
+
+	createdPR, err := h.service.Create(r.Context(), pr)
 	if err != nil {
 		status, code, msg := mapError(err)
 		respondError(w, status, code, msg)
@@ -65,7 +109,7 @@ func (h *Handler) mergePR(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	mergedPR, err := h.service.Merge(r.Context(), req.PRID)
+	mergedPR, err := h.service.Merge(r.Context(), req.PRID, req.Force)
 	if err != nil {
 		status, code, msg := mapError(err)
 		respondError(w, status, code, msg)
@@ -84,6 +128,7 @@ func (h *Handler) reassignReviewer(w http.ResponseWriter, r *http.Request) {
 		PRID          string `json:"pull_request_id"`
 		OldUserID     string `json:"old_user_id"`
 		OldReviewerID string `json:"old_reviewer_id"`
+		NewUserID     string `json:"new_user_id"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&temp); err != nil {
 		respondError(w, http.StatusBadRequest, "ERROR", "invalid json")
@@ -96,13 +141,14 @@ func (h *Handler) reassignReviewer(w http.ResponseWriter, r *http.Request) {
 	}
 	req.PRID = temp.PRID
 	req.OldUserID = targetID
+	req.NewUserID = strings.TrimSpace(temp.NewUserID)
 
 	if strings.TrimSpace(req.PRID) == "" || strings.TrimSpace(req.OldUserID) == "" {
 		respondError(w, http.StatusBadRequest, "ERROR", "pull_request_id and old_user_id are required")
 		return
 	}
 
-	newReviewerID, err := h.service.Reassign(r.Context(), req.PRID, req.OldUserID)
+	newReviewerID, err := h.service.Reassign(r.Context(), req.PRID, req.OldUserID, req.NewUserID)
 	if err != nil {
 		status, code, msg := mapError(err)
 		respondError(w, status, code, msg)
@@ -121,3 +167,207 @@ func (h *Handler) reassignReviewer(w http.ResponseWriter, r *http.Request) {
 		"replaced_by": newReviewerID,
 	})
 }
+
+// reviewPR handles the HTTP request to set the review state of an assigned reviewer.
+func (h *Handler) reviewPR(w http.ResponseWriter, r *http.Request) {
+	var req reviewPRRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "ERROR", "invalid json")
+		return
+	}
+
+	if strings.TrimSpace(req.PRID) == "" || strings.TrimSpace(req.ReviewerID) == "" {
+		respondError(w, http.StatusBadRequest, "ERROR", "pull_request_id and reviewer_id are required")
+		return
+	}
+	if !req.State.IsValid() {
+		respondError(w, http.StatusBadRequest, "ERROR", "state must be one of PENDING, APPROVED, CHANGES_REQUESTED, DISMISSED")
+		return
+	}
+
+	pr, err := h.service.SubmitReview(r.Context(), req.PRID, req.ReviewerID, req.State)
+	if err != nil {
+		status, code, msg := mapError(err)
+		respondError(w, status, code, msg)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"pr": pr,
+	})
+}
+
+// declineReview handles the HTTP request of a reviewer declining their assignment on a pull request.
+func (h *Handler) declineReview(w http.ResponseWriter, r *http.Request) {
+	var req declinePRRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "ERROR", "invalid json")
+		return
+	}
+
+	if strings.TrimSpace(req.PRID) == "" || strings.TrimSpace(req.ReviewerID) == "" {
+		respondError(w, http.StatusBadRequest, "ERROR", "pull_request_id and reviewer_id are required")
+		return
+	}
+	if !req.Reason.IsValid() {
+		respondError(w, http.StatusBadRequest, "ERROR", "reason must be one of NO_CONTEXT, OVERLOADED, CONFLICT_OF_INTEREST")
+		return
+	}
+
+	newReviewerID, err := h.service.Decline(r.Context(), req.PRID, req.ReviewerID, req.Reason)
+	if err != nil {
+		status, code, msg := mapError(err)
+		respondError(w, status, code, msg)
+		return
+	}
+
+	pr, err := h.service.GetPR(r.Context(), req.PRID)
+	if err != nil {
+		status, code, msg := mapError(err)
+		respondError(w, status, code, msg)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"pr":          pr,
+		"replaced_by": newReviewerID,
+	})
+}
+
+// getDeclines handles the HTTP request to list the declines recorded for a pull request.
+func (h *Handler) getDeclines(w http.ResponseWriter, r *http.Request) {
+	prID := r.URL.Query().Get("pull_request_id")
+	if prID == "" {
+		respondError(w, http.StatusBadRequest, "ERROR", "pull_request_id is required")
+		return
+	}
+
+	declines, err := h.service.GetDeclines(r.Context(), prID)
+	if err != nil {
+		status, code, msg := mapError(err)
+		respondError(w, status, code, msg)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"pull_request_id": prID,
+		"declines":        declines,
+	})
+}
+
+func (h *Handler) getPRHistory(w http.ResponseWriter, r *http.Request) {
+	prID := r.URL.Query().Get("pull_request_id")
+	if prID == "" {
+		respondError(w, http.StatusBadRequest, "ERROR", "pull_request_id is required")
+		return
+	}
+
+	events, err := h.service.GetPRHistory(r.Context(), prID)
+	if err != nil {
+		status, code, msg := mapError(err)
+		respondError(w, status, code, msg)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"pull_request_id": prID,
+		"events":          events,
+	})
+}
+
+// addReviewer handles the HTTP request to assign a specific reviewer to a pull request.
+func (h *Handler) addReviewer(w http.ResponseWriter, r *http.Request) {
+	h.changePRReviewer(w, r, h.service.AddReviewer)
+}
+
+// removeReviewer handles the HTTP request to unassign a reviewer from a pull request without replacement.
+func (h *Handler) removeReviewer(w http.ResponseWriter, r *http.Request) {
+	h.changePRReviewer(w, r, h.service.RemoveReviewer)
+}
+
+// changePRReviewer decodes a pull request and reviewer id pair and applies a reviewer change to them.
+func (h *Handler) changePRReviewer(
+	w http.ResponseWriter,
+	r *http.Request,
+	change func(ctx context.Context, prID, reviewerID string) (*domain.PullRequest, error),
+) {
+	var req prReviewerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "ERROR", "invalid json")
+		return
+	}
+
+	if strings.TrimSpace(req.PRID) == "" || strings.TrimSpace(req.ReviewerID) == "" {
+		respondError(w, http.StatusBadRequest, "ERROR", "pull_request_id and reviewer_id are required")
+		return
+	}
+
+	pr, err := change(r.Context(), req.PRID, req.ReviewerID)
+	if err != nil {
+		status, code, msg := mapError(err)
+		respondError(w, status, code, msg)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"pr": pr,
+	})
+}
+
+// readyPR handles the HTTP request to mark a draft pull request as ready for review.
+func (h *Handler) readyPR(w http.ResponseWriter, r *http.Request) {
+	h.changePRStatus(w, r, h.service.Ready)
+}
+
+// closePR handles the HTTP request to close a pull request without merging.
+func (h *Handler) closePR(w http.ResponseWriter, r *http.Request) {
+	h.changePRStatus(w, r, h.service.Close)
+}
+
+// reopenPR handles the HTTP request to reopen a closed pull request.
+func (h *Handler) reopenPR(w http.ResponseWriter, r *http.Request) {
+	h.changePRStatus(w, r, h.service.Reopen)
+}
+
+// changePRStatus decodes a pull request id and applies a status transition to it.
+func (h *Handler) changePRStatus(
+	w http.ResponseWriter,
+	r *http.Request,
+	transition func(ctx context.Context, prID string) (*domain.PullRequest, error),
+) {
+	var req prStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "ERROR", "invalid json")
+		return
+	}
+
+	if strings.TrimSpace(req.PRID) == "" {
+		respondError(w, http.StatusBadRequest, "ERROR", "pull_request_id is required")
+		return
+	}
+
+	pr, err := transition(r.Context(), req.PRID)
+	if err != nil {
+		status, code, msg := mapError(err)
+		respondError(w, status, code, msg)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"pr": pr,
+	})
+}
+
+// getUnassignedPRs handles the HTTP request to list open pull requests waiting for reviewers.
+func (h *Handler) getUnassignedPRs(w http.ResponseWriter, r *http.Request) {
+	prs, err := h.service.GetUnassigned(r.Context())
+	if err != nil {
+		status, code, msg := mapError(err)
+		respondError(w, status, code, msg)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"pull_requests": prs,
+	})
+}
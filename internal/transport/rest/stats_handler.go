@@ -1,6 +1,9 @@
 package rest
 
-import "net/http"
+import (
+	"net/http"
+	"strconv"
+)
 
 // getStats handles the HTTP request to retrieve system statistics.
 func (h *Handler) getStats(w http.ResponseWriter, r *http.Request) {
@@ -12,3 +15,43 @@ func (h *Handler) getStats(w http.ResponseWriter, r *http.Request) {
 	}
 	respondJSON(w, http.StatusOK, stats)
 }
+
+// getPairings handles the HTTP request to retrieve the author-to-reviewer pairing matrix of a team.
+func (h *Handler) getPairings(w http.ResponseWriter, r *http.Request) {
+	teamName := r.URL.Query().Get("team_name")
+	if teamName == "" {
+		respondError(w, http.StatusBadRequest, "ERROR", "team_name is required")
+		return
+	}
+
+	days := 0
+	if raw := r.URL.Query().Get("days"); raw != "" {
+		var err error
+		days, err = strconv.Atoi(raw)
+		if err != nil || days < 1 {
+			respondError(w, http.StatusBadRequest, "ERROR", "days must be a positive integer")
+			return
+		}
+	}
+
+	matrix, err := h.service.GetPairingMatrix(r.Context(), teamName, days)
+	if err != nil {
+		status, code, msg := mapError(err)
+		respondError(w, status, code, msg)
+		return
+	}
+	respondJSON(w, http.StatusOK, matrix)
+}
+
+// getOverdueReviews handles the HTTP request to list reviews past their SLA deadline, optionally for one team.
+func (h *Handler) getOverdueReviews(w http.ResponseWriter, r *http.Request) {
+	reviews, err := h.service.GetOverdueReviews(r.Context(), r.URL.Query().Get("team_name"))
+	if err != nil {
+		status, code, msg := mapError(err)
+		respondError(w, status, code, msg)
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"reviews": reviews,
+	})
+}
@@ -2,18 +2,29 @@ package rest
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/neizhmak/avito-review-service/internal/domain"
 )
 
 type createTeamRequest struct {
-	TeamName string        `json:"team_name"`
-	Members  []domain.User `json:"members,omitempty"`
+	TeamName string          `json:"team_name"`
+	Settings json.RawMessage `json:"settings,omitempty"`
+	Members  []domain.User   `json:"members,omitempty"`
+}
+
+type addHolidayRequest struct {
+	TeamName string `json:"team_name"`
+	Date     string `json:"date"`
+	Name     string `json:"name"`
 }
 
 type deactivateTeamRequest struct {
 	TeamName string `json:"team_name"`
+	DryRun   bool   `json:"dry_run"`
 }
 
 func (h *Handler) createTeam(w http.ResponseWriter, r *http.Request) {
@@ -33,12 +44,27 @@ func (h *Handler) createTeam(w http.ResponseWriter, r *http.Request) {
 			respondError(w, http.StatusBadRequest, "ERROR", "member user_id and username are required")
 			return
 		}
+		if m.Role != "" && !m.Role.IsValid() {
+			respondError(w, http.StatusBadRequest, "ERROR", "member role must be one of junior, middle, senior, lead")
+			return
+		}
 	}
 
-	createdTeam, err := h.service.CreateTeam(r.Context(), domain.Team{
+	team := domain.Team{
 		Name:    req.TeamName,
 		Members: req.Members,
-	})
+	}
+	if len(req.Settings) > 0 {
+		// fields missing from the request keep their default values
+		settings := domain.DefaultTeamSettings(req.TeamName)
+		if err := json.Unmarshal(req.Settings, &settings); err != nil {
+			respondError(w, http.StatusBadRequest, "ERROR", "invalid settings")
+			return
+		}
+		team.Settings = &settings
+	}
+
+	createdTeam, err := h.service.CreateTeam(r.Context(), team)
 	if err != nil {
 		status, code, msg := mapError(err)
 		respondError(w, status, code, msg)
@@ -74,11 +100,150 @@ func (h *Handler) deactivateTeam(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.DeactivateTeam(r.Context(), req.TeamName); err != nil {
+	report, err := h.service.DeactivateTeam(r.Context(), req.TeamName, req.DryRun)
+	if err != nil {
+		status, code, msg := mapError(err)
+		respondError(w, status, code, msg)
+		return
+	}
+
+	status := "deactivated"
+	if req.DryRun {
+		status = "dry_run"
+	}
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":        status,
+		"pull_requests": report,
+	})
+}
+
+func (h *Handler) getTeamSettings(w http.ResponseWriter, r *http.Request) {
+	teamName := r.URL.Query().Get("team_name")
+	if teamName == "" {
+		respondError(w, http.StatusBadRequest, "ERROR", "team_name is required")
+		return
+	}
+
+	settings, err := h.service.GetTeamSettings(r.Context(), teamName)
+	if err != nil {
+		status, code, msg := mapError(err)
+		respondError(w, status, code, msg)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"settings": settings,
+	})
+}
+
+// updateTeamSettings applies the fields present in the request on top of the current team settings.
+func (h *Handler) updateTeamSettings(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "ERROR", "invalid body")
+		return
+	}
+
+	var req struct {
+		TeamName string `json:"team_name"`
+	}
+	if err = json.Unmarshal(body, &req); err != nil {
+		respondError(w, http.StatusBadRequest, "ERROR", "invalid json")
+		return
+	}
+	if strings.TrimSpace(req.TeamName) == "" {
+		respondError(w, http.StatusBadRequest, "ERROR", "team_name is required")
+		return
+	}
+
+	settings, err := h.service.GetTeamSettings(r.Context(), req.TeamName)
+	if err != nil {
+		status, code, msg := mapError(err)
+		respondError(w, status, code, msg)
+		return
+	}
+	if err = json.Unmarshal(body, settings); err != nil {
+		respondError(w, http.StatusBadRequest, "ERROR", "invalid json")
+		return
+	}
+	settings.TeamName = req.TeamName
+
+	updated, err := h.service.UpdateTeamSettings(r.Context(), *settings)
+	if err != nil {
+		status, code, msg := mapError(err)
+		respondError(w, status, code, msg)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"settings": updated,
+	})
+}
+
+func (h *Handler) getHolidays(w http.ResponseWriter, r *http.Request) {
+	teamName := r.URL.Query().Get("team_name")
+	if teamName == "" {
+		respondError(w, http.StatusBadRequest, "ERROR", "team_name is required")
+		return
+	}
+
+	holidays, err := h.service.GetHolidays(r.Context(), teamName)
+	if err != nil {
+		status, code, msg := mapError(err)
+		respondError(w, status, code, msg)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"holidays": holidays,
+	})
+}
+
+func (h *Handler) addHoliday(w http.ResponseWriter, r *http.Request) {
+	var req addHolidayRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "ERROR", "invalid json")
+		return
+	}
+
+	if req.TeamName == "" {
+		respondError(w, http.StatusBadRequest, "ERROR", "team_name is required")
+		return
+	}
+	if _, err := time.Parse(time.DateOnly, req.Date); err != nil {
+		respondError(w, http.StatusBadRequest, "ERROR", "date must be formatted as YYYY-MM-DD")
+		return
+	}
+
+	holiday, err := h.service.AddHoliday(r.Context(), domain.Holiday{TeamName: req.TeamName, Date: req.Date, Name: req.Name})
+	if err != nil {
+		status, code, msg := mapError(err)
+		respondError(w, status, code, msg)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, map[string]interface{}{
+		"holiday": holiday,
+	})
+}
+
+func (h *Handler) deleteHoliday(w http.ResponseWriter, r *http.Request) {
+	teamName := r.URL.Query().Get("team_name")
+	date := r.URL.Query().Get("date")
+	if teamName == "" || date == "" {
+		respondError(w, http.StatusBadRequest, "ERROR", "team_name and date are required")
+		return
+	}
+	if _, err := time.Parse(time.DateOnly, date); err != nil {
+		respondError(w, http.StatusBadRequest, "ERROR", "date must be formatted as YYYY-MM-DD")
+		return
+	}
+
+	if err := h.service.DeleteHoliday(r.Context(), teamName, date); err != nil {
 		status, code, msg := mapError(err)
 		respondError(w, status, code, msg)
 		return
 	}
 
-	respondJSON(w, http.StatusOK, map[string]string{"status": "deactivated"})
+	respondJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
 }
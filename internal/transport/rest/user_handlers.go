@@ -3,7 +3,9 @@ package rest
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/neizhmak/avito-review-service/internal/domain"
 )
@@ -13,6 +15,33 @@ type setUserActiveRequest struct {
 	IsActive bool   `json:"is_active"`
 }
 
+type setUserCapacityRequest struct {
+	UserID         string `json:"user_id"`
+	MaxOpenReviews *int   `json:"max_open_reviews"`
+}
+
+type setUserRoleRequest struct {
+	UserID string          `json:"user_id"`
+	Role   domain.UserRole `json:"role"`
+}
+
+type updateUserRequest struct {
+	UserID        string    `json:"user_id"`
+	Username      *string   `json:"username"`
+	Skills        *[]string `json:"skills"`
+	TimeZone      *string   `json:"time_zone"`
+	WorkStartHour *int      `json:"work_start_hour"`
+	WorkEndHour   *int      `json:"work_end_hour"`
+	WorkDays      *[]int    `json:"work_days"`
+}
+
+type addUnavailabilityRequest struct {
+	UserID   string    `json:"user_id"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Reason   string    `json:"reason"`
+}
+
 func (h *Handler) setUserActive(w http.ResponseWriter, r *http.Request) {
 	var req setUserActiveRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -25,7 +54,96 @@ func (h *Handler) setUserActive(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	updatedUser, err := h.service.SetUserActive(r.Context(), req.UserID, req.IsActive)
+	updatedUser, report, err := h.service.SetUserActive(r.Context(), req.UserID, req.IsActive)
+	if err != nil {
+		status, code, msg := mapError(err)
+		respondError(w, status, code, msg)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"user":       updatedUser,
+		"reassigned": report.Reassigned,
+		"unfilled":   report.Unfilled,
+	})
+}
+
+func (h *Handler) setUserCapacity(w http.ResponseWriter, r *http.Request) {
+	var req setUserCapacityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "ERROR", "invalid json")
+		return
+	}
+
+	if strings.TrimSpace(req.UserID) == "" {
+		respondError(w, http.StatusBadRequest, "ERROR", "user_id is required")
+		return
+	}
+
+	updatedUser, err := h.service.SetUserCapacity(r.Context(), req.UserID, req.MaxOpenReviews)
+	if err != nil {
+		status, code, msg := mapError(err)
+		respondError(w, status, code, msg)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"user": updatedUser,
+	})
+}
+
+func (h *Handler) setUserRole(w http.ResponseWriter, r *http.Request) {
+	var req setUserRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "ERROR", "invalid json")
+		return
+	}
+
+	if strings.TrimSpace(req.UserID) == "" {
+		respondError(w, http.StatusBadRequest, "ERROR", "user_id is required")
+		return
+	}
+	if !req.Role.IsValid() {
+		respondError(w, http.StatusBadRequest, "ERROR", "role must be one of junior, middle, senior, lead")
+		return
+	}
+
+	updatedUser, err := h.service.SetUserRole(r.Context(), req.UserID, req.Role)
+	if err != nil {
+		status, code, msg := mapError(err)
+		respondError(w, status, code, msg)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"user": updatedUser,
+	})
+}
+
+func (h *Handler) updateUser(w http.ResponseWriter, r *http.Request) {
+	var req updateUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "ERROR", "invalid json")
+		return
+	}
+
+	if strings.TrimSpace(req.UserID) == "" {
+		respondError(w, http.StatusBadRequest, "ERROR", "user_id is required")
+		return
+	}
+	if req.Username != nil && strings.TrimSpace(*req.Username) == "" {
+		respondError(w, http.StatusBadRequest, "ERROR", "username must not be empty")
+		return
+	}
+
+	updatedUser, err := h.service.UpdateUser(r.Context(), req.UserID, domain.UserUpdate{
+		Username:      req.Username,
+		Skills:        req.Skills,
+		TimeZone:      req.TimeZone,
+		WorkStartHour: req.WorkStartHour,
+		WorkEndHour:   req.WorkEndHour,
+		WorkDays:      req.WorkDays,
+	})
 	if err != nil {
 		status, code, msg := mapError(err)
 		respondError(w, status, code, msg)
@@ -60,3 +178,69 @@ func (h *Handler) getUserReviews(w http.ResponseWriter, r *http.Request) {
 		"pull_requests": prs,
 	})
 }
+
+func (h *Handler) getUnavailability(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		respondError(w, http.StatusBadRequest, "ERROR", "user_id is required")
+		return
+	}
+
+	periods, err := h.service.GetUnavailability(r.Context(), userID)
+	if err != nil {
+		status, code, msg := mapError(err)
+		respondError(w, status, code, msg)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"user_id": userID,
+		"periods": periods,
+	})
+}
+
+func (h *Handler) addUnavailability(w http.ResponseWriter, r *http.Request) {
+	var req addUnavailabilityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "ERROR", "invalid json")
+		return
+	}
+
+	if strings.TrimSpace(req.UserID) == "" {
+		respondError(w, http.StatusBadRequest, "ERROR", "user_id is required")
+		return
+	}
+
+	period, err := h.service.AddUnavailability(r.Context(), domain.Unavailability{
+		UserID:   req.UserID,
+		StartsAt: req.StartsAt,
+		EndsAt:   req.EndsAt,
+		Reason:   req.Reason,
+	})
+	if err != nil {
+		status, code, msg := mapError(err)
+		respondError(w, status, code, msg)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, map[string]interface{}{
+		"period": period,
+	})
+}
+
+func (h *Handler) deleteUnavailability(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if userID == "" || err != nil {
+		respondError(w, http.StatusBadRequest, "ERROR", "user_id and numeric id are required")
+		return
+	}
+
+	if err = h.service.DeleteUnavailability(r.Context(), userID, id); err != nil {
+		status, code, msg := mapError(err)
+		respondError(w, status, code, msg)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
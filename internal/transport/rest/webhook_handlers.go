@@ -0,0 +1,126 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/neizhmak/avito-review-service/internal/domain"
+)
+
+type createWebhookRequest struct {
+	URL        string                    `json:"url"`
+	EventTypes []domain.WebhookEventType `json:"event_types"`
+	Secret     string                    `json:"secret"`
+}
+
+type redeliverWebhookRequest struct {
+	DeliveryID int64 `json:"delivery_id"`
+}
+
+// getWebhooks handles the HTTP request to list webhook subscriptions.
+func (h *Handler) getWebhooks(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := h.service.ListWebhooks(r.Context())
+	if err != nil {
+		status, code, msg := mapError(err)
+		respondError(w, status, code, msg)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"webhooks": webhooks,
+	})
+}
+
+// createWebhook handles the HTTP request to subscribe a URL to events.
+func (h *Handler) createWebhook(w http.ResponseWriter, r *http.Request) {
+	var req createWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "ERROR", "invalid json")
+		return
+	}
+
+	if strings.TrimSpace(req.URL) == "" || len(req.EventTypes) == 0 {
+		respondError(w, http.StatusBadRequest, "ERROR", "url and event_types are required")
+		return
+	}
+
+	webhook, err := h.service.CreateWebhook(r.Context(), domain.Webhook{
+		URL:        strings.TrimSpace(req.URL),
+		EventTypes: req.EventTypes,
+		Secret:     req.Secret,
+	})
+	if err != nil {
+		status, code, msg := mapError(err)
+		respondError(w, status, code, msg)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, map[string]interface{}{
+		"webhook": webhook,
+	})
+}
+
+// deleteWebhook handles the HTTP request to remove a webhook subscription.
+func (h *Handler) deleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "ERROR", "numeric id is required")
+		return
+	}
+
+	if err = h.service.DeleteWebhook(r.Context(), id); err != nil {
+		status, code, msg := mapError(err)
+		respondError(w, status, code, msg)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+// getWebhookDeliveries handles the HTTP request to show the delivery log of a webhook.
+func (h *Handler) getWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.URL.Query().Get("webhook_id"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "ERROR", "numeric webhook_id is required")
+		return
+	}
+
+	deliveries, err := h.service.GetWebhookDeliveries(r.Context(), id)
+	if err != nil {
+		status, code, msg := mapError(err)
+		respondError(w, status, code, msg)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"webhook_id": id,
+		"deliveries": deliveries,
+	})
+}
+
+// redeliverWebhook handles the HTTP request to send an earlier delivery again.
+func (h *Handler) redeliverWebhook(w http.ResponseWriter, r *http.Request) {
+	var req redeliverWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "ERROR", "invalid json")
+		return
+	}
+
+	if req.DeliveryID <= 0 {
+		respondError(w, http.StatusBadRequest, "ERROR", "delivery_id is required")
+		return
+	}
+
+	delivery, err := h.service.RedeliverWebhook(r.Context(), req.DeliveryID)
+	if err != nil {
+		status, code, msg := mapError(err)
+		respondError(w, status, code, msg)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, map[string]interface{}{
+		"delivery": delivery,
+	})
+}
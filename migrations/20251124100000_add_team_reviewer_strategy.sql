@@ -0,0 +1,10 @@
+-- +goose Up
+-- SQL section 'Up' is executed when you run 'goose up'
+
+-- NULL means the team has not chosen a strategy and follows the service default.
+ALTER TABLE teams ADD COLUMN reviewer_strategy TEXT;
+
+-- +goose Down
+-- SQL section 'Down' is executed when you run 'goose down'
+
+ALTER TABLE teams DROP COLUMN IF EXISTS reviewer_strategy;
@@ -0,0 +1,28 @@
+-- +goose Up
+-- SQL section 'Up' is executed when you run 'goose up'
+
+CREATE TABLE team_settings (
+    team_name TEXT PRIMARY KEY REFERENCES teams (name) ON DELETE CASCADE,
+    reviewer_count INT NOT NULL DEFAULT 2 CHECK (reviewer_count >= 1),
+    min_reviewers INT NOT NULL DEFAULT 0 CHECK (min_reviewers >= 0),
+    reviewer_strategy TEXT NOT NULL DEFAULT 'least_loaded',
+    allow_cross_team_fallback BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK (min_reviewers <= reviewer_count)
+);
+
+INSERT INTO team_settings (team_name, reviewer_strategy)
+SELECT name, COALESCE(reviewer_strategy, 'least_loaded') FROM teams;
+
+ALTER TABLE teams DROP COLUMN reviewer_strategy;
+
+-- +goose Down
+-- SQL section 'Down' is executed when you run 'goose down'
+
+ALTER TABLE teams ADD COLUMN reviewer_strategy TEXT;
+
+UPDATE teams t SET reviewer_strategy = s.reviewer_strategy
+FROM team_settings s
+WHERE s.team_name = t.name;
+
+DROP TABLE IF EXISTS team_settings;
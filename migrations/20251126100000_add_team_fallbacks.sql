@@ -0,0 +1,19 @@
+-- +goose Up
+-- SQL section 'Up' is executed when you run 'goose up'
+
+CREATE TABLE team_fallbacks (
+    team_name TEXT NOT NULL REFERENCES teams (name) ON DELETE CASCADE,
+    fallback_team TEXT NOT NULL REFERENCES teams (name) ON DELETE CASCADE,
+    position INT NOT NULL,
+    PRIMARY KEY (team_name, fallback_team),
+    CHECK (team_name <> fallback_team)
+);
+
+ALTER TABLE pr_reviewers ADD COLUMN fallback_team TEXT;
+
+-- +goose Down
+-- SQL section 'Down' is executed when you run 'goose down'
+
+ALTER TABLE pr_reviewers DROP COLUMN IF EXISTS fallback_team;
+
+DROP TABLE IF EXISTS team_fallbacks;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- SQL section 'Up' is executed when you run 'goose up'
+
+CREATE TABLE pull_request_files (
+    pull_request_id TEXT NOT NULL REFERENCES pull_requests (id) ON DELETE CASCADE,
+    path TEXT NOT NULL,
+    PRIMARY KEY (pull_request_id, path)
+);
+
+CREATE TABLE code_owner_rules (
+    position INT PRIMARY KEY,
+    pattern TEXT NOT NULL,
+    user_ids TEXT[] NOT NULL DEFAULT '{}',
+    team_names TEXT[] NOT NULL DEFAULT '{}'
+);
+
+-- +goose Down
+-- SQL section 'Down' is executed when you run 'goose down'
+
+DROP TABLE IF EXISTS code_owner_rules;
+
+DROP TABLE IF EXISTS pull_request_files;
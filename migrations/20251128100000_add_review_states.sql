@@ -0,0 +1,21 @@
+-- +goose Up
+-- SQL section 'Up' is executed when you run 'goose up'
+
+ALTER TABLE pr_reviewers
+    ADD COLUMN state TEXT NOT NULL DEFAULT 'PENDING'
+        CHECK (state IN ('PENDING', 'APPROVED', 'CHANGES_REQUESTED', 'DISMISSED')),
+    ADD COLUMN assigned_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    ADD COLUMN state_updated_at TIMESTAMP;
+
+-- existing reviewers were assigned when their pull request was created, not when this migration ran
+UPDATE pr_reviewers rev SET assigned_at = pr.created_at
+FROM pull_requests pr
+WHERE pr.id = rev.pull_request_id AND pr.created_at IS NOT NULL;
+
+-- +goose Down
+-- SQL section 'Down' is executed when you run 'goose down'
+
+ALTER TABLE pr_reviewers
+    DROP COLUMN IF EXISTS state_updated_at,
+    DROP COLUMN IF EXISTS assigned_at,
+    DROP COLUMN IF EXISTS state;
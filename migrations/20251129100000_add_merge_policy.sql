@@ -0,0 +1,17 @@
+-- +goose Up
+-- SQL section 'Up' is executed when you run 'goose up'
+
+ALTER TABLE team_settings
+    ADD COLUMN required_approvals INT NOT NULL DEFAULT 0 CHECK (required_approvals >= 0),
+    ADD COLUMN block_on_changes_requested BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE pull_requests ADD COLUMN force_merged BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+-- SQL section 'Down' is executed when you run 'goose down'
+
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS force_merged;
+
+ALTER TABLE team_settings
+    DROP COLUMN IF EXISTS block_on_changes_requested,
+    DROP COLUMN IF EXISTS required_approvals;
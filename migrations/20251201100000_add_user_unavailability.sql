@@ -0,0 +1,18 @@
+-- +goose Up
+-- SQL section 'Up' is executed when you run 'goose up'
+
+CREATE TABLE user_unavailability (
+    id BIGSERIAL PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX idx_user_unavailability_user_ends ON user_unavailability (user_id, ends_at);
+
+-- +goose Down
+-- SQL section 'Down' is executed when you run 'goose down'
+
+DROP TABLE IF EXISTS user_unavailability;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- SQL section 'Up' is executed when you run 'goose up'
+
+ALTER TABLE team_settings
+    ADD COLUMN max_open_reviews INT NOT NULL DEFAULT 0 CHECK (max_open_reviews >= 0),
+    ADD COLUMN queue_when_full BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE users ADD COLUMN max_open_reviews INT CHECK (max_open_reviews >= 1);
+
+-- +goose Down
+-- SQL section 'Down' is executed when you run 'goose down'
+
+ALTER TABLE users DROP COLUMN IF EXISTS max_open_reviews;
+
+ALTER TABLE team_settings
+    DROP COLUMN IF EXISTS queue_when_full,
+    DROP COLUMN IF EXISTS max_open_reviews;
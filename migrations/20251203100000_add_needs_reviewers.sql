@@ -0,0 +1,17 @@
+-- +goose Up
+-- SQL section 'Up' is executed when you run 'goose up'
+
+ALTER TABLE pull_requests ADD COLUMN needs_reviewers BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE pull_requests pr SET needs_reviewers = TRUE
+WHERE pr.status = 'OPEN'
+AND NOT EXISTS (SELECT 1 FROM pr_reviewers rev WHERE rev.pull_request_id = pr.id);
+
+CREATE INDEX idx_pull_requests_needs_reviewers ON pull_requests (created_at) WHERE needs_reviewers;
+
+-- +goose Down
+-- SQL section 'Down' is executed when you run 'goose down'
+
+DROP INDEX IF EXISTS idx_pull_requests_needs_reviewers;
+
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS needs_reviewers;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- SQL section 'Up' is executed when you run 'goose up'
+
+CREATE TABLE review_declines (
+    pull_request_id TEXT NOT NULL REFERENCES pull_requests (id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    reason TEXT NOT NULL CHECK (reason IN ('NO_CONTEXT', 'OVERLOADED', 'CONFLICT_OF_INTEREST')),
+    declined_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (pull_request_id, user_id)
+);
+
+-- +goose Down
+-- SQL section 'Down' is executed when you run 'goose down'
+
+DROP TABLE IF EXISTS review_declines;
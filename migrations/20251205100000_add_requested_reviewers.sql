@@ -0,0 +1,11 @@
+-- +goose Up
+-- SQL section 'Up' is executed when you run 'goose up'
+
+ALTER TABLE pull_requests ADD COLUMN requested_reviewers TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE pull_requests ADD COLUMN excluded_reviewers TEXT[] NOT NULL DEFAULT '{}';
+
+-- +goose Down
+-- SQL section 'Down' is executed when you run 'goose down'
+
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS excluded_reviewers;
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS requested_reviewers;
@@ -0,0 +1,20 @@
+-- +goose Up
+-- SQL section 'Up' is executed when you run 'goose up'
+
+CREATE TABLE reviewer_exclusions (
+    id BIGSERIAL PRIMARY KEY,
+    reviewer_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    author_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    mutual BOOLEAN NOT NULL DEFAULT FALSE,
+    until TIMESTAMPTZ,
+    reason TEXT NOT NULL DEFAULT '',
+    CHECK (reviewer_id <> author_id)
+);
+
+CREATE INDEX idx_reviewer_exclusions_author ON reviewer_exclusions (author_id);
+CREATE INDEX idx_reviewer_exclusions_reviewer ON reviewer_exclusions (reviewer_id);
+
+-- +goose Down
+-- SQL section 'Down' is executed when you run 'goose down'
+
+DROP TABLE IF EXISTS reviewer_exclusions;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- SQL section 'Up' is executed when you run 'goose up'
+
+ALTER TABLE team_settings
+    ADD COLUMN pairing_window_days INT NOT NULL DEFAULT 0 CHECK (pairing_window_days >= 0);
+
+-- +goose Down
+-- SQL section 'Down' is executed when you run 'goose down'
+
+ALTER TABLE team_settings DROP COLUMN IF EXISTS pairing_window_days;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- SQL section 'Up' is executed when you run 'goose up'
+
+ALTER TABLE users
+    ADD COLUMN role TEXT NOT NULL DEFAULT 'middle' CHECK (role IN ('junior', 'middle', 'senior', 'lead'));
+
+ALTER TABLE team_settings
+    ADD COLUMN require_senior BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN shadow_reviewer BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE pr_reviewers ADD COLUMN shadow BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+-- SQL section 'Down' is executed when you run 'goose down'
+
+ALTER TABLE pr_reviewers DROP COLUMN IF EXISTS shadow;
+
+ALTER TABLE team_settings
+    DROP COLUMN IF EXISTS shadow_reviewer,
+    DROP COLUMN IF EXISTS require_senior;
+
+ALTER TABLE users DROP COLUMN IF EXISTS role;
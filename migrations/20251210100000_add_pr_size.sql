@@ -0,0 +1,26 @@
+-- +goose Up
+-- SQL section 'Up' is executed when you run 'goose up'
+
+ALTER TABLE pull_requests
+    ADD COLUMN lines_added INT,
+    ADD COLUMN lines_removed INT,
+    ADD COLUMN files_changed INT,
+    ADD COLUMN size TEXT CHECK (size IN ('XS', 'S', 'M', 'L', 'XL'));
+
+CREATE TABLE team_size_reviewer_counts (
+    team_name TEXT NOT NULL REFERENCES teams (name) ON DELETE CASCADE,
+    size TEXT NOT NULL CHECK (size IN ('XS', 'S', 'M', 'L', 'XL')),
+    reviewer_count INT NOT NULL CHECK (reviewer_count > 0),
+    PRIMARY KEY (team_name, size)
+);
+
+-- +goose Down
+-- SQL section 'Down' is executed when you run 'goose down'
+
+DROP TABLE IF EXISTS team_size_reviewer_counts;
+
+ALTER TABLE pull_requests
+    DROP COLUMN IF EXISTS size,
+    DROP COLUMN IF EXISTS files_changed,
+    DROP COLUMN IF EXISTS lines_removed,
+    DROP COLUMN IF EXISTS lines_added;
@@ -0,0 +1,25 @@
+-- +goose Up
+-- SQL section 'Up' is executed when you run 'goose up'
+
+ALTER TABLE pr_reviewers
+    ADD COLUMN due_at TIMESTAMPTZ,
+    ADD COLUMN escalated_at TIMESTAMPTZ;
+
+CREATE INDEX idx_pr_reviewers_due_at ON pr_reviewers (due_at) WHERE escalated_at IS NULL;
+
+ALTER TABLE team_settings
+    ADD COLUMN review_sla_hours INT NOT NULL DEFAULT 0 CHECK (review_sla_hours >= 0),
+    ADD COLUMN sla_escalation TEXT CHECK (sla_escalation IN ('NOTIFY', 'REASSIGN', 'ADD_LEAD'));
+
+-- +goose Down
+-- SQL section 'Down' is executed when you run 'goose down'
+
+ALTER TABLE team_settings
+    DROP COLUMN IF EXISTS sla_escalation,
+    DROP COLUMN IF EXISTS review_sla_hours;
+
+DROP INDEX IF EXISTS idx_pr_reviewers_due_at;
+
+ALTER TABLE pr_reviewers
+    DROP COLUMN IF EXISTS escalated_at,
+    DROP COLUMN IF EXISTS due_at;
@@ -0,0 +1,28 @@
+-- +goose Up
+-- SQL section 'Up' is executed when you run 'goose up'
+
+ALTER TABLE users
+    ADD COLUMN time_zone TEXT NOT NULL DEFAULT 'UTC',
+    ADD COLUMN work_start_hour INT NOT NULL DEFAULT 9,
+    ADD COLUMN work_end_hour INT NOT NULL DEFAULT 18,
+    ADD COLUMN work_days INT[] NOT NULL DEFAULT '{1,2,3,4,5}',
+    ADD CONSTRAINT users_work_hours_check CHECK (0 <= work_start_hour AND work_start_hour < work_end_hour AND work_end_hour <= 24);
+
+CREATE TABLE team_holidays (
+    team_name TEXT NOT NULL REFERENCES teams (name) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    name TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (team_name, day)
+);
+
+-- +goose Down
+-- SQL section 'Down' is executed when you run 'goose down'
+
+DROP TABLE IF EXISTS team_holidays;
+
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS users_work_hours_check,
+    DROP COLUMN IF EXISTS work_days,
+    DROP COLUMN IF EXISTS work_end_hour,
+    DROP COLUMN IF EXISTS work_start_hour,
+    DROP COLUMN IF EXISTS time_zone;
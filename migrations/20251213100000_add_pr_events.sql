@@ -0,0 +1,21 @@
+-- +goose Up
+-- SQL section 'Up' is executed when you run 'goose up'
+
+CREATE TABLE pr_events (
+    id BIGSERIAL PRIMARY KEY,
+    pull_request_id TEXT NOT NULL REFERENCES pull_requests (id) ON DELETE CASCADE,
+    event_type TEXT NOT NULL CHECK (event_type IN ('ASSIGNED', 'REASSIGNED', 'REMOVED', 'DECLINED', 'MERGED', 'ESCALATED')),
+    reviewer_id TEXT NOT NULL DEFAULT '',
+    previous_reviewer_id TEXT NOT NULL DEFAULT '',
+    actor TEXT NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    strategy TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_pr_events_pull_request_id ON pr_events (pull_request_id, id);
+
+-- +goose Down
+-- SQL section 'Down' is executed when you run 'goose down'
+
+DROP TABLE IF EXISTS pr_events;
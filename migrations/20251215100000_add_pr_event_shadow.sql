@@ -0,0 +1,13 @@
+-- +goose Up
+-- SQL section 'Up' is executed when you run 'goose up'
+
+ALTER TABLE pr_events ADD COLUMN shadow BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_pr_events_created_at ON pr_events (created_at);
+
+-- +goose Down
+-- SQL section 'Down' is executed when you run 'goose down'
+
+DROP INDEX IF EXISTS idx_pr_events_created_at;
+
+ALTER TABLE pr_events DROP COLUMN IF EXISTS shadow;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- SQL section 'Up' is executed when you run 'goose up'
+
+ALTER TABLE team_settings ADD COLUMN round_robin_cursor TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+-- SQL section 'Down' is executed when you run 'goose down'
+
+ALTER TABLE team_settings DROP COLUMN IF EXISTS round_robin_cursor;